@@ -0,0 +1,394 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nouuu/gonamer/cmd/cli/ui"
+	"github.com/nouuu/gonamer/internal/cache"
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/pkg/config"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	purgeOlderThan     time.Duration
+	purgeOlderThanFlag = "older-than"
+	exportPattern      string
+	exportPatternFlag  = "pattern"
+	warmFile           string
+	warmFileFlag       = "file"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and manage the TMDB cache.",
+	Long:  `Inspect and manage the cache used to store TMDB lookups between runs.`,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show entry counts and hit rates per key family.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withCacheManager(func(ctx context.Context, manager *cache.Manager) error {
+			stats, err := manager.Stats(ctx)
+			if err != nil {
+				ui.ShowError(ctx, "Error reading cache statistics: %v", err)
+				return err
+			}
+
+			data := pterm.TableData{{"Family", "Entries", "Hits", "Misses", "Hit rate"}}
+			for _, s := range stats {
+				data = append(data, []string{
+					s.Family,
+					strconv.Itoa(s.Entries),
+					strconv.FormatInt(s.Hits, 10),
+					strconv.FormatInt(s.Misses, 10),
+					fmt.Sprintf("%.1f%%", s.HitRate()*100),
+				})
+			}
+			return pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+		})
+	},
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list [pattern]",
+	Short: "List cache keys, optionally filtered by a glob pattern such as 'search:movie:*'.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withCacheManager(func(ctx context.Context, manager *cache.Manager) error {
+			entries, err := manager.List(ctx, firstArg(args))
+			if err != nil {
+				ui.ShowError(ctx, "Error listing cache entries: %v", err)
+				return err
+			}
+
+			data := pterm.TableData{{"Key", "Family", "Expires"}}
+			for _, entry := range entries {
+				expires := "never"
+				if !entry.ExpiresAt.IsZero() {
+					expires = entry.ExpiresAt.Format(time.DateTime)
+				}
+				data = append(data, []string{entry.Key, entry.Family, expires})
+			}
+			if err := pterm.DefaultTable.WithHasHeader().WithData(data).Render(); err != nil {
+				return err
+			}
+			ui.ShowInfo(ctx, "%d entries", len(entries))
+			return nil
+		})
+	},
+}
+
+var cacheGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a cache entry as JSON.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withCacheManager(func(ctx context.Context, manager *cache.Manager) error {
+			entry, err := manager.Get(ctx, args[0])
+			if err != nil {
+				ui.ShowError(ctx, "Error reading cache entry '%s': %v", args[0], err)
+				return err
+			}
+
+			out, err := json.MarshalIndent(entry, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		})
+	},
+}
+
+var cachePurgeCmd = &cobra.Command{
+	Use:   "purge [pattern]",
+	Short: "Delete cache entries matching a glob pattern and/or older than a given age.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && purgeOlderThan <= 0 {
+			return errors.New("a pattern or --older-than is required, use '*' to purge everything")
+		}
+		return withCacheManager(func(ctx context.Context, manager *cache.Manager) error {
+			purged, err := manager.Purge(ctx, firstArg(args), purgeOlderThan)
+			if err != nil {
+				ui.ShowError(ctx, "Error purging cache: %v", err)
+				return err
+			}
+			ui.ShowSuccess(ctx, "Purged %d entries", purged)
+			return nil
+		})
+	},
+}
+
+var cacheExportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Export cache entries to JSON (stdout if no file is given).",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withCacheManager(func(ctx context.Context, manager *cache.Manager) error {
+			var w io.Writer = os.Stdout
+			if file := firstArg(args); file != "" && file != "-" {
+				f, err := os.Create(file)
+				if err != nil {
+					ui.ShowError(ctx, "Error creating export file '%s': %v", file, err)
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+
+			exported, err := manager.Export(ctx, w, exportPattern)
+			if err != nil {
+				ui.ShowError(ctx, "Error exporting cache: %v", err)
+				return err
+			}
+			if w != os.Stdout {
+				ui.ShowSuccess(ctx, "Exported %d entries", exported)
+			}
+			return nil
+		})
+	},
+}
+
+var cacheImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import cache entries from a JSON export.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withCacheManager(func(ctx context.Context, manager *cache.Manager) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				ui.ShowError(ctx, "Error opening import file '%s': %v", args[0], err)
+				return err
+			}
+			defer f.Close()
+
+			imported, err := manager.Import(ctx, f)
+			if err != nil {
+				ui.ShowError(ctx, "Error importing cache: %v", err)
+				return err
+			}
+			ui.ShowSuccess(ctx, "Imported %d entries", imported)
+			return nil
+		})
+	},
+}
+
+var cacheWarmCmd = &cobra.Command{
+	Use:   "warm [id or title]...",
+	Short: "Prefetch TMDB data for a list of IDs or titles.",
+	Long: `Prefetch TMDB data for a list of IDs or titles so later runs are served from the cache.
+Numeric items are used as TMDB IDs, anything else is searched and the first result is kept.
+Use --type to choose between movies and TV shows.`,
+	RunE: runCacheWarm,
+}
+
+func init() {
+	cachePurgeCmd.Flags().DurationVar(&purgeOlderThan, purgeOlderThanFlag, 0, "only purge entries older than this duration (e.g. 72h)")
+	cacheExportCmd.Flags().StringVar(&exportPattern, exportPatternFlag, "", "only export keys matching this glob pattern")
+	cacheWarmCmd.Flags().StringVar(&warmFile, warmFileFlag, "", "read IDs or titles from a file, one per line")
+
+	cacheCmd.AddCommand(cacheStatsCmd, cacheListCmd, cacheGetCmd, cachePurgeCmd, cacheExportCmd, cacheImportCmd, cacheWarmCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+func runCacheWarm(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	if err := initLogger(ctx); err != nil {
+		return err
+	}
+
+	items, err := readWarmItems(args, warmFile)
+	if err != nil {
+		ui.ShowError(ctx, "Error reading items to warm: %v", err)
+		return err
+	}
+	if len(items) == 0 {
+		return errors.New("no IDs or titles to warm")
+	}
+
+	cfg, err := config.LoadConfig(cfgFile)
+	if err != nil {
+		ui.ShowError(ctx, "Failed to load configuration file '%s': %v", cfgFile, err)
+		return err
+	}
+	if cmd.Flags().Changed(mediaTypeFlag) {
+		cfg.Renamer.Type = config.MediaType(mediaType)
+	}
+	if cmd.Flags().Changed(languageFlag) {
+		cfg.API.TMDB.Language = language
+	}
+	if cmd.Flags().Changed(searchLanguagesFlag) {
+		cfg.API.TMDB.SearchLanguages = searchLanguages
+	}
+	if cmd.Flags().Changed(namingLanguageFlag) {
+		cfg.API.TMDB.NamingLanguage = namingLanguage
+	}
+	// Warming is pointless without TMDB
+	cfg.API.TMDB.Offline = false
+	if err := cfg.Validate(); err != nil {
+		ui.ShowError(ctx, "Invalid configuration: %v", err)
+		return err
+	}
+
+	cacheClient, err := cache.New(ctx, cfg.Cache)
+	if err != nil {
+		ui.ShowError(ctx, "Error creating cache client: %v", err)
+		return err
+	}
+	defer func() {
+		if err := cacheClient.Close(); err != nil {
+			ui.ShowError(ctx, "Error saving cache: %v", err)
+		}
+	}()
+
+	movieClient, tvShowClient, err := newMediaClients(ctx, cfg, cacheClient)
+	if err != nil {
+		return err
+	}
+
+	warmed := 0
+	for _, item := range items {
+		var title string
+		switch cfg.Renamer.Type {
+		case config.TvShow:
+			title, err = warmTvShow(ctx, tvShowClient, item)
+		default:
+			title, err = warmMovie(ctx, movieClient, item)
+		}
+		if err != nil {
+			ui.ShowError(ctx, "Error warming '%s': %v", item, err)
+			continue
+		}
+		ui.ShowInfo(ctx, "Warmed %s", pterm.Yellow(title))
+		warmed++
+	}
+
+	ui.ShowSuccess(ctx, "Warmed %d/%d items", warmed, len(items))
+	return nil
+}
+
+func warmMovie(ctx context.Context, client mediadata.MovieClient, item string) (string, error) {
+	id := item
+	if _, err := strconv.Atoi(item); err != nil {
+		results, err := client.SearchMovie(ctx, item, 0, 1)
+		if err != nil {
+			return "", err
+		}
+		if len(results.Movies) == 0 {
+			return "", errors.New("no movie found")
+		}
+		id = results.Movies[0].ID
+	}
+
+	if _, err := client.GetMovie(ctx, id); err != nil {
+		return "", err
+	}
+	details, err := client.GetMovieDetails(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s (%s)", details.Title, details.Year), nil
+}
+
+func warmTvShow(ctx context.Context, client mediadata.TvShowClient, item string) (string, error) {
+	id := item
+	if _, err := strconv.Atoi(item); err != nil {
+		results, err := client.SearchTvShow(ctx, item, 0, 1)
+		if err != nil {
+			return "", err
+		}
+		if len(results.TvShows) == 0 {
+			return "", errors.New("no tv show found")
+		}
+		id = results.TvShows[0].ID
+	}
+
+	if _, err := client.GetTvShow(ctx, id); err != nil {
+		return "", err
+	}
+	details, err := client.GetTvShowDetails(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	for _, season := range details.Seasons {
+		if season.EpisodeCount == 0 {
+			continue
+		}
+		if _, err := client.GetSeasonEpisodes(ctx, id, season.SeasonNumber); err != nil {
+			return "", fmt.Errorf("season %d: %w", season.SeasonNumber, err)
+		}
+	}
+	return fmt.Sprintf("%s (%s), %d seasons", details.Title, details.Year, len(details.Seasons)), nil
+}
+
+func readWarmItems(args []string, file string) ([]string, error) {
+	items := append([]string{}, args...)
+	if file == "" {
+		return items, nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			items = append(items, line)
+		}
+	}
+	return items, scanner.Err()
+}
+
+// withCacheManager loads the configuration, opens the configured cache backend
+// and saves it back once fn returns, whether fn succeeded or not.
+func withCacheManager(fn func(ctx context.Context, manager *cache.Manager) error) (err error) {
+	ctx := context.Background()
+	if err := initLogger(ctx); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(cfgFile)
+	if err != nil {
+		ui.ShowError(ctx, "Failed to load configuration file '%s': %v", cfgFile, err)
+		return err
+	}
+
+	manager, err := cache.NewManager(ctx, cfg.Cache)
+	if err != nil {
+		ui.ShowError(ctx, "Error opening cache: %v", err)
+		return err
+	}
+
+	defer func() {
+		if closeErr := manager.Close(); closeErr != nil {
+			ui.ShowError(ctx, "Error saving cache: %v", closeErr)
+			err = errors.Join(err, closeErr)
+		}
+	}()
+
+	return fn(ctx, manager)
+}
+
+func firstArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
@@ -3,6 +3,9 @@ package cli
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/nouuu/gonamer/cmd/cli/handlers"
 	"github.com/nouuu/gonamer/cmd/cli/ui"
@@ -10,6 +13,7 @@ import (
 	"github.com/nouuu/gonamer/internal/mediarenamer"
 	"github.com/nouuu/gonamer/internal/mediascanner"
 	"github.com/nouuu/gonamer/pkg/config"
+	"github.com/nouuu/gonamer/pkg/logger"
 	"github.com/pterm/pterm"
 )
 
@@ -19,6 +23,8 @@ type Cli struct {
 	mediaRenamer *mediarenamer.MediaRenamer
 	tvClient     mediadata.TvShowClient
 	movieClient  mediadata.MovieClient
+	needsOnline  []string
+	duplicates   *mediarenamer.DuplicateTracker
 }
 
 var ErrExit = errors.New("exit requested")
@@ -30,17 +36,22 @@ func NewCli(scanner mediascanner.MediaScanner, mediaRenamer *mediarenamer.MediaR
 		mediaRenamer: mediaRenamer,
 		movieClient:  movieClient,
 		tvClient:     tvClient,
+		duplicates:   mediarenamer.NewDuplicateTracker(),
 	}
 }
 
 func (c *Cli) Run(ctx context.Context) error {
+	var err error
 	switch c.config.Renamer.Type {
 	case config.Movie:
-		return c.processMovie(ctx)
+		err = c.processMovie(ctx)
 	case config.TvShow:
-		return c.processTvShow(ctx)
+		err = c.processTvShow(ctx)
 	}
-	return nil
+	if retryErr := c.writeRetryList(ctx); retryErr != nil && err == nil {
+		err = retryErr
+	}
+	return err
 }
 
 func (c *Cli) processMovie(ctx context.Context) error {
@@ -69,25 +80,35 @@ func (c *Cli) processMoviesList(ctx context.Context, movies []mediascanner.Movie
 
 		// Recherche des suggestions
 		suggestions, err := c.mediaRenamer.SuggestMovies(ctx, movie, c.config.Renamer.MaxResults, c.config)
-		if err != nil {
-			suggestions = mediarenamer.MovieSuggestions{Movie: movie}
-			ui.ShowError(ctx, "Error finding suggestions for %s: %v", movie.OriginalFilename, err)
-		}
+		if errors.Is(err, mediadata.ErrNeedsOnlineLookup) {
+			c.deferOnlineLookup(ctx, movie.FullPath, err)
+		} else {
+			if err != nil {
+				suggestions = mediarenamer.MovieSuggestions{Movie: movie}
+				ui.ShowError(ctx, "Error finding suggestions for %s: %v", movie.OriginalFilename, err)
+			}
 
-		// Création et exécution du handler
-		handler := handlers.NewMovieHandler(
-			handlers.NewBaseHandler(c.config),
-			suggestions,
-			c.movieClient,
-			c.mediaRenamer,
-			func() error { return ErrExit },
-		)
-
-		if err := handler.Handle(ctx); err != nil {
-			if errors.Is(err, ErrExit) {
-				return c.Exit()
+			// Création et exécution du handler
+			base := handlers.NewBaseHandler(c.config)
+			base.Duplicates = c.duplicates
+			handler := handlers.NewMovieHandler(
+				base,
+				suggestions,
+				c.movieClient,
+				c.mediaRenamer,
+				func() error { return ErrExit },
+			)
+
+			if err := handler.Handle(ctx); err != nil {
+				if errors.Is(err, ErrExit) {
+					// The files matched so far are still renamed.
+					if err := c.handleDuplicates(ctx); err != nil && !errors.Is(err, ErrExit) {
+						return err
+					}
+					return c.Exit()
+				}
+				ui.ShowError(ctx, "Error handling movie: %v", err)
 			}
-			ui.ShowError(ctx, "Error handling movie: %v", err)
 		}
 		pb, _ = pterm.DefaultProgressbar.
 			WithTotal(len(movies)).
@@ -97,7 +118,7 @@ func (c *Cli) processMoviesList(ctx context.Context, movies []mediascanner.Movie
 	}
 
 	ui.ShowSuccess(ctx, "Finished processing movies")
-	return nil
+	return c.finish(ctx)
 }
 
 func (c *Cli) processTvShow(ctx context.Context) error {
@@ -124,25 +145,35 @@ func (c *Cli) processEpisodesList(ctx context.Context, episodes []mediascanner.E
 
 		// Recherche des suggestions
 		suggestions, err := c.mediaRenamer.SuggestEpisodes(ctx, episode, c.config.Renamer.MaxResults, c.config)
-		if err != nil {
-			suggestions = mediarenamer.EpisodeSuggestions{Episode: episode}
-			ui.ShowError(ctx, "Error finding suggestions for %s: %v", episode.OriginalFilename, err)
-		}
+		if errors.Is(err, mediadata.ErrNeedsOnlineLookup) {
+			c.deferOnlineLookup(ctx, episode.FullPath, err)
+		} else {
+			if err != nil {
+				suggestions = mediarenamer.EpisodeSuggestions{Episode: episode}
+				ui.ShowError(ctx, "Error finding suggestions for %s: %v", episode.OriginalFilename, err)
+			}
 
-		// Création et exécution du handler
-		handler := handlers.NewTvShowHandler(
-			handlers.NewBaseHandler(c.config),
-			suggestions,
-			c.tvClient,
-			c.mediaRenamer,
-			func() error { return ErrExit },
-		)
-
-		if err := handler.Handle(ctx); err != nil {
-			if errors.Is(err, ErrExit) {
-				return c.Exit()
+			// Création et exécution du handler
+			base := handlers.NewBaseHandler(c.config)
+			base.Duplicates = c.duplicates
+			handler := handlers.NewTvShowHandler(
+				base,
+				suggestions,
+				c.tvClient,
+				c.mediaRenamer,
+				func() error { return ErrExit },
+			)
+
+			if err := handler.Handle(ctx); err != nil {
+				if errors.Is(err, ErrExit) {
+					// The files matched so far are still renamed.
+					if err := c.handleDuplicates(ctx); err != nil && !errors.Is(err, ErrExit) {
+						return err
+					}
+					return c.Exit()
+				}
+				ui.ShowError(ctx, "Error handling episode: %v", err)
 			}
-			ui.ShowError(ctx, "Error handling episode: %v", err)
 		}
 
 		// Redémarre la barre après le menu
@@ -154,6 +185,36 @@ func (c *Cli) processEpisodesList(ctx context.Context, episodes []mediascanner.E
 	}
 
 	ui.ShowSuccess(ctx, "Finished processing episodes")
+	return c.finish(ctx)
+}
+
+// handleDuplicates resolves the duplicates among the matched files, then
+// renames the files left in the library. ErrExit is returned once the
+// renames are done when the user exited the duplicates menu.
+func (c *Cli) handleDuplicates(ctx context.Context) error {
+	err := c.resolveDuplicates(ctx)
+	if err != nil && !errors.Is(err, ErrExit) {
+		ui.ShowError(ctx, "Error handling duplicates: %v", err)
+		return err
+	}
+	for _, rename := range c.duplicates.Renames() {
+		// The handlers already report their errors.
+		if renameErr := rename(ctx); renameErr != nil {
+			logger.FromContext(ctx).With("error", renameErr).Debug("Deferred rename failed")
+		}
+	}
+	return err
+}
+
+// finish resolves the duplicates and renames the matched files at the end of
+// a run.
+func (c *Cli) finish(ctx context.Context) error {
+	if err := c.handleDuplicates(ctx); err != nil {
+		if errors.Is(err, ErrExit) {
+			return c.Exit()
+		}
+		return err
+	}
 	return nil
 }
 
@@ -161,3 +222,25 @@ func (c *Cli) Exit() error {
 	pterm.Info.Println("Exiting...")
 	return nil
 }
+
+// deferOnlineLookup records a file that could not be matched from the cache
+// alone so it can be retried once TMDB is reachable again.
+func (c *Cli) deferOnlineLookup(ctx context.Context, path string, err error) {
+	ui.ShowWarning(ctx, "Skipping %s: %v", pterm.Yellow(filepath.Base(path)), err)
+	c.needsOnline = append(c.needsOnline, path)
+}
+
+func (c *Cli) writeRetryList(ctx context.Context) error {
+	if len(c.needsOnline) == 0 {
+		return nil
+	}
+
+	content := strings.Join(c.needsOnline, "\n") + "\n"
+	if err := os.WriteFile(c.config.Renamer.RetryFile, []byte(content), 0644); err != nil {
+		ui.ShowError(ctx, "Error writing retry list %s: %v", c.config.Renamer.RetryFile, err)
+		return err
+	}
+
+	ui.ShowWarning(ctx, "%d files need an online lookup, list written to %s", len(c.needsOnline), c.config.Renamer.RetryFile)
+	return nil
+}
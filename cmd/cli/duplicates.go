@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+
+	"github.com/nouuu/gonamer/cmd/cli/ui"
+	"github.com/nouuu/gonamer/internal/mediarenamer"
+	"github.com/nouuu/gonamer/pkg/config"
+	"github.com/pterm/pterm"
+)
+
+// resolveDuplicates shows the files of the run that matched the same movie or
+// episode and keeps one of each according to the duplicates policy. The others
+// are moved to the quarantine folder before anything is renamed, so the file
+// kept gets the name without a suffix.
+func (c *Cli) resolveDuplicates(ctx context.Context) error {
+	groups := c.duplicates.Duplicates()
+	if len(groups) == 0 {
+		return nil
+	}
+	ui.ShowWarning(ctx, "Found %d movies or episodes matched by several files", len(groups))
+
+	policy := c.config.Renamer.Duplicates
+	for g, group := range groups {
+		if err := showDuplicateGroup(group); err != nil {
+			return err
+		}
+		if policy == config.DuplicatesKeepAll {
+			continue
+		}
+		if c.config.Renamer.DryRun && policy == config.DuplicatesAsk {
+			ui.ShowInfo(ctx, "Dry run, will ask which file of %s to keep", pterm.Yellow(group.Title))
+			continue
+		}
+
+		keep := group.Best(policy)
+		if keep < 0 {
+			var err error
+			if keep, err = askDuplicate(group); err != nil {
+				// The files of the groups left unresolved stay where they are.
+				for _, unresolved := range groups[g:] {
+					for _, file := range unresolved.Files {
+						c.duplicates.Discard(file.Path)
+					}
+				}
+				return err
+			}
+		}
+		if keep < 0 {
+			continue
+		}
+
+		for i, file := range group.Files {
+			if i == keep {
+				continue
+			}
+			c.duplicates.Discard(file.Path)
+			quarantined, err := c.mediaRenamer.Quarantine(ctx, file.Path, c.config.Renamer.DryRun)
+			if err != nil {
+				ui.ShowError(ctx, "Error moving duplicate %s: %v", file.Path, err)
+				continue
+			}
+			verb := "Moved"
+			if c.config.Renamer.DryRun {
+				verb = "Dry run, would move"
+			}
+			ui.ShowInfo(ctx, "%s duplicate %s to %s, keeping %s", verb,
+				pterm.Yellow(filepath.Base(file.Path)), pterm.Yellow(quarantined), pterm.Yellow(filepath.Base(group.Files[keep].Path)))
+		}
+	}
+	return nil
+}
+
+// ReportDuplicates matches every file of the media path and lists the movies
+// or episodes found in several files. Nothing is moved, which suits an already
+// organized library.
+func (c *Cli) ReportDuplicates(ctx context.Context) error {
+	switch c.config.Renamer.Type {
+	case config.Movie:
+		movies, err := c.ScanMovies(ctx)
+		if err != nil {
+			return err
+		}
+		spinner, _ := pterm.DefaultSpinner.WithShowTimer(true).Start("Matching movies...")
+		suggestions := c.mediaRenamer.FindMovieSuggestions(ctx, movies, 1, c.config)
+		ui.HandleSpinnerStop(ctx, spinner)
+		for _, suggestion := range suggestions {
+			if len(suggestion.SuggestedMovies) > 0 {
+				c.duplicates.AddMovie(suggestion.SuggestedMovies[0], suggestion.Movie, suggestion.Movie.FullPath)
+			}
+		}
+	case config.TvShow:
+		episodes, err := c.ScanTvEpisodes(ctx)
+		if err != nil {
+			return err
+		}
+		spinner, _ := pterm.DefaultSpinner.WithShowTimer(true).Start("Matching episodes...")
+		suggestions := c.mediaRenamer.FindEpisodeSuggestions(ctx, episodes, 1, c.config)
+		ui.HandleSpinnerStop(ctx, spinner)
+		for _, suggestion := range suggestions {
+			if len(suggestion.SuggestedEpisodes) > 0 {
+				top := suggestion.SuggestedEpisodes[0]
+				c.duplicates.AddEpisode(top.TvShow, top.Episode, suggestion.Episode.FullPath)
+			}
+		}
+	}
+
+	groups := c.duplicates.Duplicates()
+	if len(groups) == 0 {
+		ui.ShowSuccess(ctx, "No duplicates found")
+		return nil
+	}
+	for _, group := range groups {
+		if err := showDuplicateGroup(group); err != nil {
+			return err
+		}
+	}
+	ui.ShowWarning(ctx, "Found %d movies or episodes matched by several files", len(groups))
+	return nil
+}
+
+func showDuplicateGroup(group mediarenamer.DuplicateGroup) error {
+	pterm.DefaultSection.Println(group.Title)
+	data := pterm.TableData{{"#", "File", "Quality"}}
+	for i, file := range group.Files {
+		data = append(data, []string{strconv.Itoa(i + 1), file.Path, file.String()})
+	}
+	return pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
+
+// askDuplicate returns the index of the file to keep, or -1 to keep them all.
+func askDuplicate(group mediarenamer.DuplicateGroup) (int, error) {
+	keep := -1
+	menu := ui.NewMenuBuilder()
+	for i, file := range group.Files {
+		menu.AddOption("Keep "+filepath.Base(file.Path)+" ("+file.String()+")", func() error {
+			keep = i
+			return nil
+		})
+	}
+	err := menu.AddOption("Keep all", func() error { return nil }).
+		AddOption("Exit", func() error { return ErrExit }).
+		Build()
+	return keep, err
+}
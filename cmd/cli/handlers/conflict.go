@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/nouuu/gonamer/cmd/cli/ui"
+	"github.com/nouuu/gonamer/internal/mediarenamer"
+	"github.com/nouuu/gonamer/pkg/config"
+	"github.com/pterm/pterm"
+)
+
+// showRenameResult reports what happened to a renamed file, kind being
+// "movie" or "episode".
+func showRenameResult(ctx context.Context, kind, filename string, result mediarenamer.RenameResult) {
+	name := pterm.Yellow(filename)
+	target := pterm.Yellow(filepath.Base(result.Destination))
+	existing := pterm.Yellow(filepath.Base(result.Existing))
+
+	switch result.Action {
+	case mediarenamer.ActionUnchanged:
+		ui.ShowSuccess(ctx, "Original filename is already correct for %s", name)
+	case mediarenamer.ActionSuffixed:
+		ui.ShowWarning(ctx, "Renamed %s %s to %s, %s already exists", kind, name, target, existing)
+	case mediarenamer.ActionSkipped:
+		ui.ShowWarning(ctx, "Skipped %s %s, keeping the existing %s (%s)", kind, name, existing, result.Reason)
+	case mediarenamer.ActionOverwritten:
+		ui.ShowWarning(ctx, "Renamed %s %s to %s, replacing the existing file (%s)", kind, name, target, result.Reason)
+	case mediarenamer.ActionQuarantined:
+		ui.ShowWarning(ctx, "Moved %s %s to %s, keeping the existing %s (%s)", kind, name, pterm.Yellow(result.Quarantined), existing, result.Reason)
+	case mediarenamer.ActionReplaced:
+		ui.ShowWarning(ctx, "Renamed %s %s to %s, the existing file was moved to %s (%s)", kind, name, target, pterm.Yellow(result.Quarantined), result.Reason)
+	case mediarenamer.ActionPending:
+		ui.ShowWarning(ctx, "Renaming %s %s to %s will ask what to do, %s already exists", kind, name, target, existing)
+	default:
+		ui.ShowInfo(ctx, "Renamed %s %s to %s", kind, name, target)
+	}
+	if result.Route != "" {
+		ui.ShowInfo(ctx, "Routing rule %s sent it to %s", pterm.Yellow(result.Route), pterm.Yellow(filepath.Dir(result.Destination)))
+	}
+}
+
+// AskConflict lets the user resolve a conflict for the "ask" policy.
+func AskConflict(ctx context.Context, conflict mediarenamer.Conflict) (config.ConflictPolicy, error) {
+	ui.ShowWarning(ctx, "%s already exists", pterm.Yellow(conflict.Existing.Path))
+	pterm.Printfln("  new:      %s (%s)", filepath.Base(conflict.Source.Path), conflict.Source)
+	pterm.Printfln("  existing: %s (%s)", filepath.Base(conflict.Existing.Path), conflict.Existing)
+
+	policy := config.ConflictSuffix
+	choose := func(p config.ConflictPolicy) func() error {
+		return func() error {
+			policy = p
+			return nil
+		}
+	}
+
+	err := ui.NewMenuBuilder().
+		AddOption("Keep both (add a suffix)", choose(config.ConflictSuffix)).
+		AddOption("Skip this file", choose(config.ConflictSkip)).
+		AddOption("Overwrite the existing file", choose(config.ConflictOverwrite)).
+		AddOption("Keep the larger file", choose(config.ConflictKeepLarger)).
+		AddOption("Keep the higher quality file", choose(config.ConflictKeepHigherQuality)).
+		AddOption("Keep the higher quality file, quarantine the other", choose(config.ConflictQuarantine)).
+		Build()
+	return policy, err
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 
+	"github.com/nouuu/gonamer/internal/mediarenamer"
 	"github.com/nouuu/gonamer/pkg/config"
 )
 
@@ -18,6 +19,9 @@ type BaseHandler struct {
 	DryRun     bool
 	QuickMode  bool
 	MaxResults int
+	// Duplicates records the matched files, when the caller looks for
+	// duplicates once all files are processed.
+	Duplicates *mediarenamer.DuplicateTracker
 }
 
 // MediaSuggestion reste l'interface commune pour les suggestions
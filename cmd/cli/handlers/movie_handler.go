@@ -8,6 +8,7 @@ import (
 	"github.com/nouuu/gonamer/cmd/cli/ui"
 	"github.com/nouuu/gonamer/internal/mediadata"
 	"github.com/nouuu/gonamer/internal/mediarenamer"
+	"github.com/nouuu/gonamer/pkg/logger"
 	"github.com/pterm/pterm"
 )
 
@@ -17,6 +18,9 @@ type MovieHandler struct {
 	movieClient  mediadata.MovieClient
 	mediaRenamer *mediarenamer.MediaRenamer
 	exitFunc     func() error
+	// collections holds the collection of each suggested movie by ID, fetched
+	// once before the menu is drawn.
+	collections map[string]string
 }
 
 func NewMovieHandler(
@@ -32,6 +36,7 @@ func NewMovieHandler(
 		movieClient:  movieClient,
 		mediaRenamer: mediaRenamer,
 		exitFunc:     exitFunc,
+		collections:  make(map[string]string),
 	}
 }
 func (h *MovieHandler) Handle(ctx context.Context) error {
@@ -41,8 +46,27 @@ func (h *MovieHandler) Handle(ctx context.Context) error {
         return nil 
     }
 	
+	if h.suggestion.Strategy != "" {
+		ui.ShowInfo(ctx, "Movies for %s found by relaxing the search: %s", pterm.Yellow(h.suggestion.Movie.OriginalFilename), h.suggestion.Strategy)
+	}
+
 	if len(h.suggestion.SuggestedMovies) != 1 {
-		return h.handleOptions(ctx)
+		return h.showOptions(ctx)
+	}
+
+	if mismatch, ok := h.suggestion.RuntimeMismatches[h.suggestion.SuggestedMovies[0].ID]; ok && h.QuickMode {
+		ui.ShowWarning(ctx, "Quick - Not renaming movie %s automatically: %s", pterm.Yellow(h.suggestion.Movie.OriginalFilename), mismatch)
+		return h.showOptions(ctx)
+	}
+
+	if lang := h.suggestion.SuggestedMovies[0].TitleLanguage; lang != "" && h.QuickMode {
+		ui.ShowWarning(ctx, "Quick - Not renaming movie %s automatically: title only known in %s", pterm.Yellow(h.suggestion.Movie.OriginalFilename), lang)
+		return h.showOptions(ctx)
+	}
+
+	if h.suggestion.Strategy != "" && h.QuickMode {
+		ui.ShowWarning(ctx, "Quick - Not renaming movie %s automatically: found by relaxing the search", pterm.Yellow(h.suggestion.Movie.OriginalFilename))
+		return h.showOptions(ctx)
 	}
 
 	if h.QuickMode {
@@ -50,7 +74,7 @@ func (h *MovieHandler) Handle(ctx context.Context) error {
 		return h.renameMovie(ctx, h.suggestion, h.suggestion.SuggestedMovies[0])
 	}
 
-	return h.handleOptions(ctx)
+	return h.showOptions(ctx)
 }
 
 func (h *MovieHandler) handleOptions(ctx context.Context) error {
@@ -59,11 +83,26 @@ func (h *MovieHandler) handleOptions(ctx context.Context) error {
 	for _, movie := range h.suggestion.SuggestedMovies {
 		movie := movie
 		label := fmt.Sprintf("%s (%s)", movie.Title, movie.Year)
+		if collection := h.collections[movie.ID]; collection != "" {
+			label += pterm.Gray(" · " + collection)
+		}
+		if movie.TitleLanguage != "" {
+			label += pterm.Yellow(" ⚠ " + movie.TitleLanguage + " title")
+		}
+		if mismatch, ok := h.suggestion.RuntimeMismatches[movie.ID]; ok {
+			label += pterm.Red(" ⚠ " + mismatch.String())
+		}
 		menuBuilder.AddOption(label, func() error {
 			return h.renameMovie(ctx, h.suggestion, movie)
 		})
 	}
 
+	if h.suggestion.Search.HasMore() {
+		menuBuilder.AddOption("More results…", func() error {
+			return h.handleMoreResults(ctx)
+		})
+	}
+
 	menuBuilder.AddOption("Search Manually", func() error {
 		return h.handleManualSearch(ctx)
 	})
@@ -85,6 +124,28 @@ func (h *MovieHandler) handleOptions(ctx context.Context) error {
 	return menuBuilder.Build()
 }
 
+// showOptions fetches what the menu needs for new suggestions, then draws it.
+func (h *MovieHandler) showOptions(ctx context.Context) error {
+	h.loadCollections(ctx)
+	return h.handleOptions(ctx)
+}
+
+// loadCollections fetches the collection of the suggested movies not looked up
+// yet. Movies whose details can't be fetched, e.g. offline without cache, are
+// shown without collection.
+func (h *MovieHandler) loadCollections(ctx context.Context) {
+	for _, movie := range h.suggestion.SuggestedMovies {
+		if _, ok := h.collections[movie.ID]; ok {
+			continue
+		}
+		details, err := h.movieClient.GetMovieDetails(ctx, movie.ID)
+		if err != nil {
+			logger.FromContext(ctx).With("error", err).Warnf("Could not get the collection of '%s'", movie.Title)
+		}
+		h.collections[movie.ID] = details.Collection.Name
+	}
+}
+
 func (h *MovieHandler) handleManualSearch(ctx context.Context) error {
 	query, err := ui.PromptText(
 		fmt.Sprintf("Search for '%s'", h.suggestion.Movie.OriginalFilename),
@@ -94,17 +155,24 @@ func (h *MovieHandler) handleManualSearch(ctx context.Context) error {
 		return err
 	}
 
-	movies, err := h.movieClient.SearchMovie(ctx, query, 0, 1)
+	suggestion, err := h.mediaRenamer.SearchMovies(ctx, h.suggestion.Movie, query, 0, h.config.Renamer.MaxResults)
 	if err != nil {
 		return fmt.Errorf("error searching for movie: %w", err)
 	}
+	h.suggestion = suggestion
 
-	h.suggestion.SuggestedMovies = movies.Movies
-	if len(h.suggestion.SuggestedMovies) > h.config.Renamer.MaxResults {
-		h.suggestion.SuggestedMovies = h.suggestion.SuggestedMovies[:h.config.Renamer.MaxResults]
+	return h.showOptions(ctx)
+}
+
+// handleMoreResults adds the next results of the search to the menu.
+func (h *MovieHandler) handleMoreResults(ctx context.Context) error {
+	suggestion, err := h.mediaRenamer.MoreMovies(ctx, h.suggestion, h.config.Renamer.MaxResults)
+	if err != nil {
+		return fmt.Errorf("error getting more results: %w", err)
 	}
+	h.suggestion = suggestion
 
-	return h.handleOptions(ctx)
+	return h.showOptions(ctx)
 }
 
 func (h *MovieHandler) handleManualRename(ctx context.Context) error {
@@ -118,7 +186,7 @@ func (h *MovieHandler) handleManualRename(ctx context.Context) error {
 
 	filename := fmt.Sprintf("%s.%s", result, h.suggestion.Movie.Extension)
 
-	finalPath, err := h.mediaRenamer.RenameFile(
+	renamed, err := h.mediaRenamer.RenameFile(
 		ctx,
 		h.suggestion.Movie.FullPath,
 		filepath.Join(filepath.Dir(h.suggestion.Movie.FullPath), filename),
@@ -129,27 +197,33 @@ func (h *MovieHandler) handleManualRename(ctx context.Context) error {
 		return err
 	}
 
-	ui.ShowInfo(ctx, "Renamed movie %s to %s", pterm.Yellow(h.suggestion.Movie.OriginalFilename), pterm.Yellow(filepath.Base(finalPath)))
+	showRenameResult(ctx, "movie", h.suggestion.Movie.OriginalFilename, renamed)
 	return nil
 
 }
 
 func (h *MovieHandler) renameMovie(ctx context.Context, suggestion mediarenamer.MovieSuggestions, movie mediadata.Movie) error {
+	if h.Duplicates != nil {
+		// Renamed once every file is matched, after the duplicates of the
+		// movie are resolved.
+		h.Duplicates.AddMovie(movie, suggestion.Movie, suggestion.Movie.FullPath)
+		h.Duplicates.Defer(suggestion.Movie.FullPath, func(ctx context.Context) error {
+			return h.applyMovieRename(ctx, suggestion, movie)
+		})
+		ui.ShowInfo(ctx, "Matched movie %s to %s, renaming it once every file is matched", pterm.Yellow(suggestion.Movie.OriginalFilename), pterm.Yellow(movie.Title))
+		return nil
+	}
+	return h.applyMovieRename(ctx, suggestion, movie)
+}
 
-	finalPath, err := h.mediaRenamer.RenameMovie(ctx, suggestion.Movie, movie, h.config.Renamer.Patterns.Movie, h.config.Renamer.DryRun)
+func (h *MovieHandler) applyMovieRename(ctx context.Context, suggestion mediarenamer.MovieSuggestions, movie mediadata.Movie) error {
+
+	result, err := h.mediaRenamer.RenameMovie(ctx, suggestion.Movie, movie, h.config.Renamer.Patterns.Movie, h.config.Renamer.DryRun)
 	if err != nil {
 		ui.ShowError(ctx, "Error renaming movie: %v", err)
 		return err
 	}
 
-	if filepath.Base(finalPath) == suggestion.Movie.OriginalFilename {
-		ui.ShowSuccess(ctx, "Original filename is already correct for %s", pterm.Yellow(suggestion.Movie.OriginalFilename))
-	} else {
-		ui.ShowInfo(ctx, "Renamed movie %s to %s",
-			pterm.Yellow(suggestion.Movie.OriginalFilename),
-			pterm.Yellow(filepath.Base(finalPath)),
-		)
-	}
-	
+	showRenameResult(ctx, "movie", suggestion.Movie.OriginalFilename, result)
 	return nil
 }
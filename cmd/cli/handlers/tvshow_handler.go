@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/nouuu/gonamer/cmd/cli/ui"
 	"github.com/nouuu/gonamer/internal/mediadata"
@@ -46,6 +48,16 @@ func (h *TvShowHandler) Handle(ctx context.Context) error {
 		return h.handleOptions(ctx)
 	}
 
+	if mismatch := h.suggestions.SuggestedEpisodes[0].RuntimeMismatch; mismatch != nil && h.QuickMode {
+		ui.ShowWarning(ctx, "Quick - Not renaming episode %s automatically: %s", pterm.Yellow(h.suggestions.Episode.OriginalFilename), mismatch)
+		return h.handleOptions(ctx)
+	}
+
+	if lang := h.suggestions.SuggestedEpisodes[0].TvShow.TitleLanguage; lang != "" && h.QuickMode {
+		ui.ShowWarning(ctx, "Quick - Not renaming episode %s automatically: show title only known in %s", pterm.Yellow(h.suggestions.Episode.OriginalFilename), lang)
+		return h.handleOptions(ctx)
+	}
+
 	if h.QuickMode {
 		ui.ShowSuccess(ctx, "Quick - Renaming episode %s", pterm.Yellow(h.suggestions.Episode.OriginalFilename))
 		suggestion := h.suggestions.SuggestedEpisodes[0]
@@ -66,11 +78,23 @@ func (h *TvShowHandler) handleOptions(ctx context.Context) error {
 			episode.Episode.EpisodeNumber,
 			episode.Episode.Name,
 		)
+		if episode.TvShow.TitleLanguage != "" {
+			label += pterm.Yellow(" ⚠ " + episode.TvShow.TitleLanguage + " title")
+		}
+		if episode.RuntimeMismatch != nil {
+			label += pterm.Red(" ⚠ " + episode.RuntimeMismatch.String())
+		}
 		menuBuilder.AddOption(label, func() error {
 			return h.renameEpisode(ctx, h.suggestions, episode.TvShow, episode.Episode)
 		})
 	}
 
+	if len(h.suggestions.SuggestedEpisodes) > 0 {
+		menuBuilder.AddOption("Pick Episode Manually", func() error {
+			return h.handlePickEpisode(ctx)
+		})
+	}
+
 	menuBuilder.AddOption("Search Manually", func() error {
 		return h.handleManualSearch(ctx)
 	})
@@ -108,9 +132,13 @@ func (h *TvShowHandler) handleManualSearch(ctx context.Context) error {
 
 	h.suggestions.SuggestedEpisodes = make([]mediarenamer.SuggestedEpisode, 0, len(tvShows.TvShows))
 	for _, tvShow := range tvShows.TvShows {
-		episode, err := h.tvClient.GetEpisode(ctx, tvShow.ID, h.suggestions.Episode.Season, h.suggestions.Episode.Episode)
+		seasonEpisodes, err := h.tvClient.GetSeasonEpisodes(ctx, tvShow.ID, h.suggestions.Episode.Season)
 		if err != nil {
-			ui.ShowError(ctx, "Error getting episode: %v", err)
+			ui.ShowError(ctx, "Error getting season %d of %s: %v", h.suggestions.Episode.Season, tvShow.Title, err)
+			continue
+		}
+		episode, ok := mediadata.FindEpisode(seasonEpisodes, h.suggestions.Episode.Episode)
+		if !ok {
 			continue
 		}
 		h.suggestions.SuggestedEpisodes = append(h.suggestions.SuggestedEpisodes, mediarenamer.SuggestedEpisode{
@@ -126,6 +154,69 @@ func (h *TvShowHandler) handleManualSearch(ctx context.Context) error {
 	return h.handleOptions(ctx)
 }
 
+// handlePickEpisode lists the episodes of a season of one of the suggested
+// shows so the right one can be picked when numbering doesn't match.
+func (h *TvShowHandler) handlePickEpisode(ctx context.Context) error {
+	shows := make([]mediadata.TvShow, 0, len(h.suggestions.SuggestedEpisodes))
+	seen := make(map[string]bool)
+	for _, suggestion := range h.suggestions.SuggestedEpisodes {
+		if !seen[suggestion.TvShow.ID] {
+			seen[suggestion.TvShow.ID] = true
+			shows = append(shows, suggestion.TvShow)
+		}
+	}
+
+	if len(shows) == 1 {
+		return h.pickEpisodeFromShow(ctx, shows[0])
+	}
+
+	menuBuilder := ui.NewMenuBuilder()
+	for _, show := range shows {
+		show := show
+		menuBuilder.AddOption(fmt.Sprintf("%s (%s)", show.Title, show.Year), func() error {
+			return h.pickEpisodeFromShow(ctx, show)
+		})
+	}
+	menuBuilder.AddOption("Back", func() error {
+		return h.handleOptions(ctx)
+	})
+	return menuBuilder.Build()
+}
+
+func (h *TvShowHandler) pickEpisodeFromShow(ctx context.Context, show mediadata.TvShow) error {
+	seasonInput, err := ui.PromptText(
+		fmt.Sprintf("Season of '%s'", show.Title),
+		strconv.Itoa(h.suggestions.Episode.Season),
+	)
+	if err != nil {
+		return err
+	}
+	season, err := strconv.Atoi(strings.TrimSpace(seasonInput))
+	if err != nil {
+		ui.ShowError(ctx, "Invalid season number '%s'", seasonInput)
+		return h.handleOptions(ctx)
+	}
+
+	episodes, err := h.tvClient.GetSeasonEpisodes(ctx, show.ID, season)
+	if err != nil {
+		ui.ShowError(ctx, "Error getting season %d of %s: %v", season, show.Title, err)
+		return h.handleOptions(ctx)
+	}
+
+	menuBuilder := ui.NewMenuBuilder()
+	for _, episode := range episodes {
+		episode := episode
+		label := fmt.Sprintf("%dx%02d - %s", episode.SeasonNumber, episode.EpisodeNumber, episode.Name)
+		menuBuilder.AddOption(label, func() error {
+			return h.renameEpisode(ctx, h.suggestions, show, episode)
+		})
+	}
+	menuBuilder.AddOption("Back", func() error {
+		return h.handleOptions(ctx)
+	})
+	return menuBuilder.Build()
+}
+
 func (h *TvShowHandler) handleManualRename(ctx context.Context) error {
 	ui.ShowInfo(ctx, "Renaming manually for %s", pterm.Yellow(h.suggestions.Episode.OriginalFilename))
 
@@ -141,7 +232,7 @@ func (h *TvShowHandler) handleManualRename(ctx context.Context) error {
 
 	filename := fmt.Sprintf("%s.%s", result, h.suggestions.Episode.Extension)
 
-	finalPath, err := h.mediaRenamer.RenameFile(
+	renamed, err := h.mediaRenamer.RenameFile(
 		ctx,
 		h.suggestions.Episode.FullPath,
 		filepath.Join(filepath.Dir(h.suggestions.Episode.FullPath), filename),
@@ -152,7 +243,7 @@ func (h *TvShowHandler) handleManualRename(ctx context.Context) error {
 		return err
 	}
 
-	ui.ShowInfo(ctx, "Renamed episode %s to %s", pterm.Yellow(h.suggestions.Episode.OriginalFilename), pterm.Yellow(filepath.Base(finalPath)))
+	showRenameResult(ctx, "episode", h.suggestions.Episode.OriginalFilename, renamed)
 	return nil
 }
 
@@ -162,21 +253,32 @@ func (h *TvShowHandler) renameEpisode(
 	tvShow mediadata.TvShow,
 	episode mediadata.Episode,
 ) error {
+	if h.Duplicates != nil {
+		// Renamed once every file is matched, after the duplicates of the
+		// episode are resolved.
+		h.Duplicates.AddEpisode(tvShow, episode, suggestion.Episode.FullPath)
+		h.Duplicates.Defer(suggestion.Episode.FullPath, func(ctx context.Context) error {
+			return h.applyEpisodeRename(ctx, suggestion, tvShow, episode)
+		})
+		ui.ShowInfo(ctx, "Matched episode %s to %s %dx%02d, renaming it once every file is matched", pterm.Yellow(suggestion.Episode.OriginalFilename), pterm.Yellow(tvShow.Title), episode.SeasonNumber, episode.EpisodeNumber)
+		return nil
+	}
+	return h.applyEpisodeRename(ctx, suggestion, tvShow, episode)
+}
 
-	finalPath, err := h.mediaRenamer.RenameEpisode(ctx, suggestion.Episode, tvShow, episode, h.config.Renamer.Patterns.TVShow, h.config.Renamer.DryRun)
+func (h *TvShowHandler) applyEpisodeRename(
+	ctx context.Context,
+	suggestion mediarenamer.EpisodeSuggestions,
+	tvShow mediadata.TvShow,
+	episode mediadata.Episode,
+) error {
+
+	result, err := h.mediaRenamer.RenameEpisode(ctx, suggestion.Episode, tvShow, episode, h.config.Renamer.Patterns.TVShow, h.config.Renamer.DryRun)
 	if err != nil {
 		ui.ShowError(ctx, "Error renaming episode: %v", err)
 		return err
 	}
 
-	if filepath.Base(finalPath) == suggestion.Episode.OriginalFilename {
-		ui.ShowSuccess(ctx, "Original filename is already correct for %s", pterm.Yellow(suggestion.Episode.OriginalFilename))
-	} else {
-		ui.ShowInfo(ctx, "Renamed episode %s to %s",
-			pterm.Yellow(suggestion.Episode.OriginalFilename),
-			pterm.Yellow(filepath.Base(finalPath)),
-		)
-	}
-	
+	showRenameResult(ctx, "episode", suggestion.Episode.OriginalFilename, result)
 	return nil
 }
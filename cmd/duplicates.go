@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/nouuu/gonamer/cmd/cli"
+	"github.com/nouuu/gonamer/cmd/cli/ui"
+	"github.com/nouuu/gonamer/internal/cache"
+	"github.com/nouuu/gonamer/internal/mediarenamer"
+	"github.com/nouuu/gonamer/internal/mediascanner/filescanner"
+	"github.com/nouuu/gonamer/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var duplicatesCmd = &cobra.Command{
+	Args:  cobra.MaximumNArgs(1),
+	Use:   "duplicates [path]",
+	Short: "List movies or episodes matched by several files.",
+	Long: `Match the media files in the specified path against TMDB and list the
+movies or episodes found in several files. Nothing is renamed or moved, so it
+can be run on an already organized library.
+If no path is specified, the media path of the configuration file is used.`,
+	RunE: runDuplicates,
+}
+
+func init() {
+	rootCmd.AddCommand(duplicatesCmd)
+}
+
+func runDuplicates(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	if err := initLogger(ctx); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(cfgFile)
+	if err != nil {
+		ui.ShowError(ctx, "Failed to load configuration file '%s': %v", cfgFile, err)
+		return err
+	}
+	if len(args) > 0 {
+		cfg.SetMediaPath(args[0])
+	}
+	if cmd.Flags().Changed(recursiveFlag) {
+		cfg.Scanner.Recursive = recursive
+	}
+	if cmd.Flags().Changed(mediaTypeFlag) {
+		cfg.Renamer.Type = config.MediaType(mediaType)
+	}
+	if cmd.Flags().Changed(languageFlag) {
+		cfg.API.TMDB.Language = language
+	}
+	if cmd.Flags().Changed(searchLanguagesFlag) {
+		cfg.API.TMDB.SearchLanguages = searchLanguages
+	}
+	if cmd.Flags().Changed(namingLanguageFlag) {
+		cfg.API.TMDB.NamingLanguage = namingLanguage
+	}
+	if cmd.Flags().Changed(offlineFlag) {
+		cfg.API.TMDB.Offline = offline
+	}
+	if err := cfg.Validate(); err != nil {
+		ui.ShowError(ctx, "Invalid configuration: %v", err)
+		return err
+	}
+
+	cacheClient, err := cache.New(ctx, cfg.Cache)
+	if err != nil {
+		ui.ShowError(ctx, "Error creating cache client: %v", err)
+		return err
+	}
+	defer func() {
+		if err := cacheClient.Close(); err != nil {
+			ui.ShowError(ctx, "Error saving cache: %v", err)
+		}
+	}()
+
+	movieClient, tvShowClient, err := newMediaClients(ctx, cfg, cacheClient)
+	if err != nil {
+		return err
+	}
+
+	mediaRenamer := mediarenamer.NewMediaRenamer(movieClient, tvShowClient, cfg.Renamer)
+	return cli.NewCli(filescanner.New(), mediaRenamer, movieClient, tvShowClient, cfg).ReportDuplicates(ctx)
+}
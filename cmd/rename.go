@@ -5,8 +5,10 @@ import (
 	"os"
 
 	"github.com/nouuu/gonamer/cmd/cli"
+	"github.com/nouuu/gonamer/cmd/cli/handlers"
 	"github.com/nouuu/gonamer/cmd/cli/ui"
 	"github.com/nouuu/gonamer/internal/cache"
+	"github.com/nouuu/gonamer/internal/mediadata"
 	"github.com/nouuu/gonamer/internal/mediadata/tmdb"
 	"github.com/nouuu/gonamer/internal/mediarenamer"
 	"github.com/nouuu/gonamer/internal/mediascanner/filescanner"
@@ -58,9 +60,18 @@ func runRename(cmd *cobra.Command, args []string) error {
 	if cmd.Flags().Changed(maxResultsFlag) {
 		cfg.Renamer.MaxResults = maxResults
 	}
+	if cmd.Flags().Changed(searchDepthFlag) {
+		cfg.Renamer.SearchDepth = searchDepth
+	}
 	if cmd.Flags().Changed(quickModeFlag) {
 		cfg.Renamer.QuickMode = quickMode
 	}
+	if cmd.Flags().Changed(onConflictFlag) {
+		cfg.Renamer.OnConflict = config.ConflictPolicy(onConflict)
+	}
+	if cmd.Flags().Changed(duplicatesFlag) {
+		cfg.Renamer.Duplicates = config.DuplicatePolicy(duplicates)
+	}
 	if cmd.Flags().Changed(mediaTypeFlag) {
 		cfg.Renamer.Type = config.MediaType(mediaType)
 	}
@@ -73,9 +84,18 @@ func runRename(cmd *cobra.Command, args []string) error {
 	if cmd.Flags().Changed(languageFlag) {
 		cfg.API.TMDB.Language = language
 	}
+	if cmd.Flags().Changed(searchLanguagesFlag) {
+		cfg.API.TMDB.SearchLanguages = searchLanguages
+	}
+	if cmd.Flags().Changed(namingLanguageFlag) {
+		cfg.API.TMDB.NamingLanguage = namingLanguage
+	}
 	if cmd.Flags().Changed(includeNotFoundFlag) {
 		cfg.Scanner.IncludeNotFound = includeNotFound
 	}
+	if cmd.Flags().Changed(offlineFlag) {
+		cfg.API.TMDB.Offline = offline
+	}
 
 	if err := cfg.Validate(); err != nil {
 		ui.ShowError(ctx, "Invalid configuration: %v", err)
@@ -102,7 +122,15 @@ func startCli(ctx context.Context, conf *config.Config, mediaPath string) error
 
 	if mediaPath != "." {
 		ui.ShowInfo(ctx, "Using media path '%s' instead of the one in the configuration file", mediaPath)
-		conf.Scanner.MediaPath = mediaPath
+		conf.SetMediaPath(mediaPath)
+	}
+	if conf.Renamer.Patterns.Preset != "" && conf.Renamer.Patterns.Root == "" {
+		// Presets lay out the whole library from the scanned folder.
+		conf.Renamer.Patterns.Root = conf.Scanner.MediaPath
+	}
+	if conf.Renamer.Sort.Language == "" {
+		// Titles come in the TMDB language, and so do their articles.
+		conf.Renamer.Sort.Language = conf.API.TMDB.NamingLang()
 	}
 
 	if conf.Renamer.DryRun {
@@ -111,29 +139,56 @@ func startCli(ctx context.Context, conf *config.Config, mediaPath string) error
 		ui.ShowWarning(ctx, "Dry run mode disabled, files will be renamed")
 	}
 
-	cacheClient, err := cache.NewGoCache(ctx)
+	if conf.API.TMDB.Offline {
+		ui.ShowWarning(ctx, "Offline mode enabled, only cached TMDB data will be used")
+	}
+
+	cacheClient, err := cache.New(ctx, conf.Cache)
 	if err != nil {
 		ui.ShowError(ctx, "Error creating cache client: %v", err)
 		return err
 	}
+	defer func() {
+		if err := cacheClient.Close(); err != nil {
+			ui.ShowError(ctx, "Error saving cache: %v", err)
+		}
+	}()
 
 	scanner := filescanner.New()
-	movieClient, err := tmdb.NewMovieClient(conf.API.TMDB.Key, cacheClient, tmdb.WithLang(conf.API.TMDB.Language))
+	movieClient, tvShowClient, err := newMediaClients(ctx, conf, cacheClient)
 	if err != nil {
-		ui.ShowError(ctx, "Error creating movie client: %v", err)
 		return err
 	}
 
-	tvShowClient, err := tmdb.NewTvShowClient(conf.API.TMDB.Key, cacheClient, tmdb.WithLang(conf.API.TMDB.Language))
-	if err != nil {
-		ui.ShowError(ctx, "Error creating tv show client: %v", err)
-		return err
-	}
-
-	mediaRenamer := mediarenamer.NewMediaRenamer(movieClient, tvShowClient)
+	mediaRenamer := mediarenamer.NewMediaRenamer(movieClient, tvShowClient, conf.Renamer)
+	mediaRenamer.SetConflictAsker(handlers.AskConflict)
 
 	newCli := cli.NewCli(scanner, mediaRenamer, movieClient, tvShowClient, conf)
 
 	return newCli.Run(ctx)
 
 }
+
+func tmdbOpts(conf config.TMDBConfig) []tmdb.OptFunc {
+	return []tmdb.OptFunc{
+		tmdb.WithLang(conf.NamingLang()),
+		tmdb.WithSearchLangs(conf.SearchLangs()...),
+		tmdb.WithOffline(conf.Offline),
+	}
+}
+
+func newMediaClients(ctx context.Context, conf *config.Config, cacheClient cache.Cache) (mediadata.MovieClient, mediadata.TvShowClient, error) {
+	movieClient, err := tmdb.NewMovieClient(conf.API.TMDB.Key, cacheClient, tmdbOpts(conf.API.TMDB)...)
+	if err != nil {
+		ui.ShowError(ctx, "Error creating movie client: %v", err)
+		return nil, nil, err
+	}
+
+	tvShowClient, err := tmdb.NewTvShowClient(conf.API.TMDB.Key, cacheClient, tmdbOpts(conf.API.TMDB)...)
+	if err != nil {
+		ui.ShowError(ctx, "Error creating tv show client: %v", err)
+		return nil, nil, err
+	}
+
+	return movieClient, tvShowClient, nil
+}
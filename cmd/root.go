@@ -24,9 +24,15 @@ var (
 	maxResults          int
 	maxResultsFlag      = "max-results"
 	maxResultsShort     = "m"
+	searchDepth         int
+	searchDepthFlag     = "search-depth"
 	quickMode           bool
 	quickModeFlag       = "quick"
 	quickModeShort      = "q"
+	onConflict          string
+	onConflictFlag      = "on-conflict"
+	duplicates          string
+	duplicatesFlag      = "duplicates"
 	mediaType           string
 	mediaTypeFlag       = "type"
 	mediaTypeShort      = "t"
@@ -39,6 +45,12 @@ var (
 	language            string
 	languageFlag        = "language"
 	languageShort       = "l"
+	searchLanguages     []string
+	searchLanguagesFlag = "search-languages"
+	namingLanguage      string
+	namingLanguageFlag  = "naming-language"
+	offline             bool
+	offlineFlag         = "offline"
 )
 
 var rootCmd = &cobra.Command{
@@ -67,7 +79,10 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&dryRun, dryRunFlag, true, "simulate renaming without actual changes")
 	rootCmd.PersistentFlags().StringVarP(&mediaType, mediaTypeFlag, mediaTypeShort, "movie", "media type (movie or tvshow)")
 	rootCmd.PersistentFlags().IntVarP(&maxResults, maxResultsFlag, maxResultsShort, 5, "maximum number of suggestions")
+	rootCmd.PersistentFlags().IntVar(&searchDepth, searchDepthFlag, 1, "pages of TMDB results ranked when matching files")
 	rootCmd.PersistentFlags().BoolVarP(&quickMode, quickModeFlag, quickModeShort, false, "quick mode without confirmation")
+	rootCmd.PersistentFlags().StringVar(&onConflict, onConflictFlag, "suffix", "when the destination exists: suffix, skip, overwrite, keep-larger, keep-higher-quality, ask or quarantine")
+	rootCmd.PersistentFlags().StringVar(&duplicates, duplicatesFlag, "ask", "when several files match the same movie or episode: ask, keep-higher-quality, keep-larger or keep-all")
 
 	// Pattern flags
 	rootCmd.PersistentFlags().StringVar(&moviePattern, moviePatternFlag, "{name} - {year}{extension}", "movie renaming pattern")
@@ -75,6 +90,9 @@ func init() {
 
 	// API flags
 	rootCmd.PersistentFlags().StringVarP(&language, languageFlag, languageShort, "en-US", "preferred language for TMDB API")
+	rootCmd.PersistentFlags().StringSliceVar(&searchLanguages, searchLanguagesFlag, nil, "languages to search TMDB in, in order (default is --language)")
+	rootCmd.PersistentFlags().StringVar(&namingLanguage, namingLanguageFlag, "", "language of the titles used in new names (default is --language)")
+	rootCmd.PersistentFlags().BoolVar(&offline, offlineFlag, false, "answer only from the cache, files that need TMDB are listed for a later run")
 
 	rootCmd.SetVersionTemplate("GoNamer {{.Version}}\n")
 }
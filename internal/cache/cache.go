@@ -3,29 +3,35 @@ package cache
 import (
 	"context"
 	"fmt"
-	"os"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/nouuu/gonamer/pkg/config"
 	"github.com/nouuu/gonamer/pkg/logger"
-	gocache "github.com/patrickmn/go-cache"
 
 	"github.com/eko/gocache/lib/v4/store"
 	"github.com/nouuu/gonamer/internal/mediadata"
 
 	"github.com/eko/gocache/lib/v4/cache"
 	"github.com/eko/gocache/lib/v4/marshaler"
-	gocache_store "github.com/eko/gocache/store/go_cache/v4"
 )
 
+// Every key carries the TMDB language right after its family so that
+// changing the language never serves data fetched in another one.
 const (
-	movieSearchKey    = "search:movie:%s:year:%d:page:%d"
-	tvShowSearchKey   = "search:tvshow:%s:year:%d:page:%d"
-	movieKey          = "movie:%s"
-	movieDetailsKey   = "movie:details:%s"
-	tvShowKey         = "tvshow:%s"
-	tvShowDetailsKey  = "tvshow:details:%s"
-	seasonEpisodesKey = "tvshow:%s:season:%d"
-	episodeKey        = "tvshow:%s:season:%d:episode:%d"
+	movieSearchKey    = "search:movie:%s:%s:year:%d:page:%d"
+	tvShowSearchKey   = "search:tvshow:%s:%s:year:%d:page:%d"
+	movieKey          = "movie:%s:%s"
+	movieDetailsKey   = "movie:details:%s:%s"
+	tvShowKey         = "tvshow:%s:%s"
+	tvShowDetailsKey  = "tvshow:details:%s:%s"
+	seasonEpisodesKey = "tvshow:%s:%s:season:%d"
+	episodeKey        = "tvshow:%s:%s:season:%d:episode:%d"
+	movieFindKey      = "find:movie:%s:%s:%s"
+	tvShowFindKey     = "find:tvshow:%s:%s:%s"
+
+	defaultLanguage = "default"
 )
 
 type Cache interface {
@@ -52,21 +58,49 @@ type Cache interface {
 	GetSeasonEpisodes(ctx context.Context, showID string, seasonNum int) ([]mediadata.Episode, error)
 	SetEpisode(ctx context.Context, showID string, seasonNum int, episodeNum int, episode mediadata.Episode) error
 	GetEpisode(ctx context.Context, showID string, seasonNum int, episodeNum int) (mediadata.Episode, error)
-}
 
-func NewGoCache(ctx context.Context) (Cache, error) {
+	// Recherches par identifiant externe (IMDb, TVDB)
+	SetMovieFind(ctx context.Context, source mediadata.ExternalSource, id string, movie mediadata.Movie) error
+	GetMovieFind(ctx context.Context, source mediadata.ExternalSource, id string) (mediadata.Movie, error)
+	SetTvShowFind(ctx context.Context, source mediadata.ExternalSource, id string, tvShow mediadata.TvShow) error
+	GetTvShowFind(ctx context.Context, source mediadata.ExternalSource, id string) (mediadata.TvShow, error)
+
+	// WithLanguage returns a view of the cache whose keys are scoped to lang
+	WithLanguage(lang string) Cache
 
-	goCacheClient := gocache.New(5*time.Minute, 10*time.Minute)
+	// Close persists pending entries to the backend
+	Close() error
+}
 
-	if err := goCacheClient.LoadFile("gonamer-cache.gob"); err != nil {
-		if !os.IsNotExist(err) {
-			return nil, fmt.Errorf("failed to load cache file: %w", err)
+// New builds the cache backend selected in the configuration. A Redis server
+// that cannot be reached is not fatal: the run continues without caching.
+func New(ctx context.Context, cfg config.CacheConfig) (Cache, error) {
+	switch cfg.Type {
+	case config.RedisCache:
+		c, err := NewRedisCache(ctx, cfg)
+		if err != nil {
+			logger.FromContext(ctx).With("error", err).Warn("redis cache unavailable, continuing without cache")
+			return NewNoCache(), nil
 		}
+		return c, nil
+	case config.NoCache:
+		return NewNoCache(), nil
+	default:
+		return NewGoCache(ctx, cfg)
 	}
+}
+
+func NewGoCache(ctx context.Context, cfg config.CacheConfig) (Cache, error) {
 
-	goCacheStore := gocache_store.NewGoCache(goCacheClient)
+	fileStore, err := openFileStore(cfg.File)
+	if err != nil {
+		return nil, err
+	}
 
+	stop := make(chan struct{})
+	done := make(chan struct{})
 	go func(ctx context.Context) {
+		defer close(done)
 		ticker := time.NewTicker(10 * time.Second)
 		defer ticker.Stop()
 
@@ -74,8 +108,10 @@ func NewGoCache(ctx context.Context) (Cache, error) {
 			select {
 			case <-ctx.Done():
 				return
+			case <-stop:
+				return
 			case <-ticker.C:
-				if err := goCacheClient.SaveFile("gonamer-cache.gob"); err != nil {
+				if err := fileStore.Save(); err != nil {
 					logger.FromContext(ctx).With("error", err).Error("failed to save cache file")
 				}
 
@@ -83,23 +119,95 @@ func NewGoCache(ctx context.Context) (Cache, error) {
 		}
 	}(ctx)
 
+	c := newStoreCache(fileStore, cfg.TTL)
+	// Close waits for the periodic save to stop so that it never writes the
+	// file at the same time as the final save.
+	c.stopSaving = sync.OnceFunc(func() {
+		close(stop)
+		<-done
+	})
+	return c, nil
+}
+
+func newStoreCache(s rawStore, ttl config.CacheTTLConfig) *goCache {
 	return &goCache{
-		marshaler: marshaler.New(cache.New[any](goCacheStore)),
-	}, nil
+		marshaler: marshaler.New(cache.New[any](s)),
+		store:     s,
+		ttl:       ttl,
+		lang:      defaultLanguage,
+	}
 }
 
 type goCache struct {
 	marshaler *marshaler.Marshaler
+	store     rawStore
+	ttl       config.CacheTTLConfig
+	lang      string
+	// stopSaving stops the periodic save of the file store, if any
+	stopSaving func()
+}
+
+func (g *goCache) WithLanguage(lang string) Cache {
+	view := *g
+	view.lang = strings.ToLower(lang)
+	if view.lang == "" {
+		view.lang = defaultLanguage
+	}
+	return &view
+}
+
+// searchExpiration returns the expiration of a search entry. Searches without any
+// result are negative entries and use their own TTL; a negative TTL below
+// zero disables caching them.
+func (g *goCache) searchExpiration(totals int64) (time.Duration, bool) {
+	if totals > 0 {
+		return g.ttl.Search, true
+	}
+	return g.ttl.Negative, g.ttl.Negative > 0
+}
+
+func (g *goCache) Close() error {
+	if g.stopSaving != nil {
+		g.stopSaving()
+	}
+	return g.store.Save()
+}
+
+// set stores value under key along with its write time, from which the
+// management commands compute the age of the entry whatever TTL it got.
+func (g *goCache) set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if err := g.marshaler.Set(ctx, key, value, store.WithExpiration(ttl)); err != nil {
+		return err
+	}
+	return g.store.Set(ctx, writtenKey(key), formatWrittenAt(time.Now()), store.WithExpiration(ttl))
+}
+
+// get reads key into returnObj and records the lookup in the hit/miss
+// counters of the key family.
+func (g *goCache) get(ctx context.Context, key string, returnObj any) (any, error) {
+	result, err := g.marshaler.Get(ctx, key, returnObj)
+	counterKey := fmt.Sprintf(missesKey, keyFamily(key))
+	if err == nil {
+		counterKey = fmt.Sprintf(hitsKey, keyFamily(key))
+	}
+	if incrErr := g.store.Incr(ctx, counterKey); incrErr != nil {
+		logger.FromContext(ctx).With("error", incrErr).Debug("failed to record cache statistics")
+	}
+	return result, err
 }
 
 func (g *goCache) SetMovieSearch(ctx context.Context, query string, year int, page int, results mediadata.MovieResults) error {
-	key := fmt.Sprintf(movieSearchKey, query, year, page)
-	return g.marshaler.Set(ctx, key, results, store.WithExpiration(1*time.Hour))
+	key := fmt.Sprintf(movieSearchKey, g.lang, normalizeQuery(query), year, page)
+	ttl, ok := g.searchExpiration(results.Totals)
+	if !ok {
+		return nil
+	}
+	return g.set(ctx, key, results, ttl)
 }
 
 func (g *goCache) GetMovieSearch(ctx context.Context, query string, year int, page int) (mediadata.MovieResults, error) {
-	key := fmt.Sprintf(movieSearchKey, query, year, page)
-	results, err := g.marshaler.Get(ctx, key, new(mediadata.MovieResults))
+	key := fmt.Sprintf(movieSearchKey, g.lang, normalizeQuery(query), year, page)
+	results, err := g.get(ctx, key, new(mediadata.MovieResults))
 	if err != nil {
 		return mediadata.MovieResults{}, err
 	}
@@ -107,13 +215,17 @@ func (g *goCache) GetMovieSearch(ctx context.Context, query string, year int, pa
 }
 
 func (g *goCache) SetTvShowSearch(ctx context.Context, query string, year int, page int, results mediadata.TvShowResults) error {
-	key := fmt.Sprintf(tvShowSearchKey, query, year, page)
-	return g.marshaler.Set(ctx, key, results, store.WithExpiration(1*time.Hour))
+	key := fmt.Sprintf(tvShowSearchKey, g.lang, normalizeQuery(query), year, page)
+	ttl, ok := g.searchExpiration(results.Totals)
+	if !ok {
+		return nil
+	}
+	return g.set(ctx, key, results, ttl)
 }
 
 func (g *goCache) GetTvShowSearch(ctx context.Context, query string, year int, page int) (mediadata.TvShowResults, error) {
-	key := fmt.Sprintf(tvShowSearchKey, query, year, page)
-	results, err := g.marshaler.Get(ctx, key, new(mediadata.TvShowResults))
+	key := fmt.Sprintf(tvShowSearchKey, g.lang, normalizeQuery(query), year, page)
+	results, err := g.get(ctx, key, new(mediadata.TvShowResults))
 	if err != nil {
 		return mediadata.TvShowResults{}, err
 	}
@@ -122,13 +234,13 @@ func (g *goCache) GetTvShowSearch(ctx context.Context, query string, year int, p
 
 // Films
 func (g *goCache) SetMovie(ctx context.Context, id string, movie mediadata.Movie) error {
-	key := fmt.Sprintf(movieKey, id)
-	return g.marshaler.Set(ctx, key, movie, store.WithExpiration(24*time.Hour))
+	key := fmt.Sprintf(movieKey, g.lang, id)
+	return g.set(ctx, key, movie, g.ttl.Movie)
 }
 
 func (g *goCache) GetMovie(ctx context.Context, id string) (mediadata.Movie, error) {
-	key := fmt.Sprintf(movieKey, id)
-	result, err := g.marshaler.Get(ctx, key, new(mediadata.Movie))
+	key := fmt.Sprintf(movieKey, g.lang, id)
+	result, err := g.get(ctx, key, new(mediadata.Movie))
 	if err != nil {
 		return mediadata.Movie{}, err
 	}
@@ -136,13 +248,13 @@ func (g *goCache) GetMovie(ctx context.Context, id string) (mediadata.Movie, err
 }
 
 func (g *goCache) SetMovieDetails(ctx context.Context, id string, details mediadata.MovieDetails) error {
-	key := fmt.Sprintf(movieDetailsKey, id)
-	return g.marshaler.Set(ctx, key, details, store.WithExpiration(24*time.Hour))
+	key := fmt.Sprintf(movieDetailsKey, g.lang, id)
+	return g.set(ctx, key, details, g.ttl.Movie)
 }
 
 func (g *goCache) GetMovieDetails(ctx context.Context, id string) (mediadata.MovieDetails, error) {
-	key := fmt.Sprintf(movieDetailsKey, id)
-	result, err := g.marshaler.Get(ctx, key, new(mediadata.MovieDetails))
+	key := fmt.Sprintf(movieDetailsKey, g.lang, id)
+	result, err := g.get(ctx, key, new(mediadata.MovieDetails))
 	if err != nil {
 		return mediadata.MovieDetails{}, err
 	}
@@ -151,13 +263,13 @@ func (g *goCache) GetMovieDetails(ctx context.Context, id string) (mediadata.Mov
 
 // Séries
 func (g *goCache) SetTvShow(ctx context.Context, id string, tvShow mediadata.TvShow) error {
-	key := fmt.Sprintf(tvShowKey, id)
-	return g.marshaler.Set(ctx, key, tvShow, store.WithExpiration(24*time.Hour))
+	key := fmt.Sprintf(tvShowKey, g.lang, id)
+	return g.set(ctx, key, tvShow, g.ttl.TvShow)
 }
 
 func (g *goCache) GetTvShow(ctx context.Context, id string) (mediadata.TvShow, error) {
-	key := fmt.Sprintf(tvShowKey, id)
-	result, err := g.marshaler.Get(ctx, key, new(mediadata.TvShow))
+	key := fmt.Sprintf(tvShowKey, g.lang, id)
+	result, err := g.get(ctx, key, new(mediadata.TvShow))
 	if err != nil {
 		return mediadata.TvShow{}, err
 	}
@@ -165,13 +277,13 @@ func (g *goCache) GetTvShow(ctx context.Context, id string) (mediadata.TvShow, e
 }
 
 func (g *goCache) SetTvShowDetails(ctx context.Context, id string, details mediadata.TvShowDetails) error {
-	key := fmt.Sprintf(tvShowDetailsKey, id)
-	return g.marshaler.Set(ctx, key, details, store.WithExpiration(24*time.Hour))
+	key := fmt.Sprintf(tvShowDetailsKey, g.lang, id)
+	return g.set(ctx, key, details, g.ttl.TvShow)
 }
 
 func (g *goCache) GetTvShowDetails(ctx context.Context, id string) (mediadata.TvShowDetails, error) {
-	key := fmt.Sprintf(tvShowDetailsKey, id)
-	result, err := g.marshaler.Get(ctx, key, new(mediadata.TvShowDetails))
+	key := fmt.Sprintf(tvShowDetailsKey, g.lang, id)
+	result, err := g.get(ctx, key, new(mediadata.TvShowDetails))
 	if err != nil {
 		return mediadata.TvShowDetails{}, err
 	}
@@ -180,13 +292,13 @@ func (g *goCache) GetTvShowDetails(ctx context.Context, id string) (mediadata.Tv
 
 // Episodes
 func (g *goCache) SetSeasonEpisodes(ctx context.Context, showID string, seasonNum int, episodes []mediadata.Episode) error {
-	key := fmt.Sprintf(seasonEpisodesKey, showID, seasonNum)
-	return g.marshaler.Set(ctx, key, episodes, store.WithExpiration(24*time.Hour))
+	key := fmt.Sprintf(seasonEpisodesKey, g.lang, showID, seasonNum)
+	return g.set(ctx, key, episodes, g.ttl.Season)
 }
 
 func (g *goCache) GetSeasonEpisodes(ctx context.Context, showID string, seasonNum int) ([]mediadata.Episode, error) {
-	key := fmt.Sprintf(seasonEpisodesKey, showID, seasonNum)
-	result, err := g.marshaler.Get(ctx, key, new([]mediadata.Episode))
+	key := fmt.Sprintf(seasonEpisodesKey, g.lang, showID, seasonNum)
+	result, err := g.get(ctx, key, new([]mediadata.Episode))
 	if err != nil {
 		return nil, err
 	}
@@ -194,15 +306,44 @@ func (g *goCache) GetSeasonEpisodes(ctx context.Context, showID string, seasonNu
 }
 
 func (g *goCache) SetEpisode(ctx context.Context, showID string, seasonNum int, episodeNum int, episode mediadata.Episode) error {
-	key := fmt.Sprintf(episodeKey, showID, seasonNum, episodeNum)
-	return g.marshaler.Set(ctx, key, episode, store.WithExpiration(24*time.Hour))
+	key := fmt.Sprintf(episodeKey, g.lang, showID, seasonNum, episodeNum)
+	return g.set(ctx, key, episode, g.ttl.Season)
 }
 
 func (g *goCache) GetEpisode(ctx context.Context, showID string, seasonNum int, episodeNum int) (mediadata.Episode, error) {
-	key := fmt.Sprintf(episodeKey, showID, seasonNum, episodeNum)
-	result, err := g.marshaler.Get(ctx, key, new(mediadata.Episode))
+	key := fmt.Sprintf(episodeKey, g.lang, showID, seasonNum, episodeNum)
+	result, err := g.get(ctx, key, new(mediadata.Episode))
 	if err != nil {
 		return mediadata.Episode{}, err
 	}
 	return *result.(*mediadata.Episode), nil
 }
+
+// Recherches par identifiant externe
+func (g *goCache) SetMovieFind(ctx context.Context, source mediadata.ExternalSource, id string, movie mediadata.Movie) error {
+	key := fmt.Sprintf(movieFindKey, g.lang, source, id)
+	return g.set(ctx, key, movie, g.ttl.Movie)
+}
+
+func (g *goCache) GetMovieFind(ctx context.Context, source mediadata.ExternalSource, id string) (mediadata.Movie, error) {
+	key := fmt.Sprintf(movieFindKey, g.lang, source, id)
+	result, err := g.get(ctx, key, new(mediadata.Movie))
+	if err != nil {
+		return mediadata.Movie{}, err
+	}
+	return *result.(*mediadata.Movie), nil
+}
+
+func (g *goCache) SetTvShowFind(ctx context.Context, source mediadata.ExternalSource, id string, tvShow mediadata.TvShow) error {
+	key := fmt.Sprintf(tvShowFindKey, g.lang, source, id)
+	return g.set(ctx, key, tvShow, g.ttl.TvShow)
+}
+
+func (g *goCache) GetTvShowFind(ctx context.Context, source mediadata.ExternalSource, id string) (mediadata.TvShow, error) {
+	key := fmt.Sprintf(tvShowFindKey, g.lang, source, id)
+	result, err := g.get(ctx, key, new(mediadata.TvShow))
+	if err != nil {
+		return mediadata.TvShow{}, err
+	}
+	return *result.(*mediadata.TvShow), nil
+}
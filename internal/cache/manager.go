@@ -0,0 +1,388 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eko/gocache/lib/v4/store"
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/pkg/config"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	statsPrefix = "stats:"
+	hitsKey     = statsPrefix + "%s:hits"
+	missesKey   = statsPrefix + "%s:misses"
+
+	// writtenPrefix keys the write time of every entry, which expires with it.
+	writtenPrefix = "written:"
+)
+
+const (
+	FamilyMovieSearch   = "search:movie"
+	FamilyTvShowSearch  = "search:tvshow"
+	FamilyMovie         = "movie"
+	FamilyMovieDetails  = "movie:details"
+	FamilyTvShow        = "tvshow"
+	FamilyTvShowDetails = "tvshow:details"
+	FamilySeason        = "tvshow:season"
+	FamilyEpisode       = "tvshow:episode"
+	FamilyMovieFind     = "find:movie"
+	FamilyTvShowFind    = "find:tvshow"
+	FamilyUnknown       = "unknown"
+)
+
+type family struct {
+	name     string
+	match    *regexp.Regexp
+	ttl      func(config.CacheTTLConfig) time.Duration
+	newValue func() any
+}
+
+// families is ordered from the most to the least specific key layout.
+var families = []family{
+	{FamilyMovieSearch, regexp.MustCompile(`^search:movie:`), searchTTL, func() any { return new(mediadata.MovieResults) }},
+	{FamilyTvShowSearch, regexp.MustCompile(`^search:tvshow:`), searchTTL, func() any { return new(mediadata.TvShowResults) }},
+	{FamilyMovieDetails, regexp.MustCompile(`^movie:details:`), movieTTL, func() any { return new(mediadata.MovieDetails) }},
+	{FamilyMovie, regexp.MustCompile(`^movie:`), movieTTL, func() any { return new(mediadata.Movie) }},
+	{FamilyTvShowDetails, regexp.MustCompile(`^tvshow:details:`), tvShowTTL, func() any { return new(mediadata.TvShowDetails) }},
+	{FamilyEpisode, regexp.MustCompile(`^tvshow:[^:]+:[^:]+:season:\d+:episode:\d+$`), seasonTTL, func() any { return new(mediadata.Episode) }},
+	{FamilySeason, regexp.MustCompile(`^tvshow:[^:]+:[^:]+:season:\d+$`), seasonTTL, func() any { return new([]mediadata.Episode) }},
+	{FamilyTvShow, regexp.MustCompile(`^tvshow:`), tvShowTTL, func() any { return new(mediadata.TvShow) }},
+	{FamilyMovieFind, regexp.MustCompile(`^find:movie:`), movieTTL, func() any { return new(mediadata.Movie) }},
+	{FamilyTvShowFind, regexp.MustCompile(`^find:tvshow:`), tvShowTTL, func() any { return new(mediadata.TvShow) }},
+}
+
+func searchTTL(ttl config.CacheTTLConfig) time.Duration { return ttl.Search }
+func movieTTL(ttl config.CacheTTLConfig) time.Duration  { return ttl.Movie }
+func tvShowTTL(ttl config.CacheTTLConfig) time.Duration { return ttl.TvShow }
+func seasonTTL(ttl config.CacheTTLConfig) time.Duration { return ttl.Season }
+
+func lookupFamily(key string) (family, bool) {
+	for _, f := range families {
+		if f.match.MatchString(key) {
+			return f, true
+		}
+	}
+	return family{}, false
+}
+
+// keyFamily returns the family of a cache key, such as "search:movie" or "tvshow:details".
+func keyFamily(key string) string {
+	if f, ok := lookupFamily(key); ok {
+		return f.name
+	}
+	return FamilyUnknown
+}
+
+// Entry is a single cache entry as exposed by the management commands.
+type Entry struct {
+	Key       string    `json:"key"`
+	Family    string    `json:"family"`
+	WrittenAt time.Time `json:"written_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Value     any       `json:"value,omitempty"`
+}
+
+// Age returns how long ago the entry was written. Entries written before
+// their write time was recorded fall back to an estimate from their
+// remaining lifetime and the TTL configured for their family.
+func (e Entry) Age(ttl config.CacheTTLConfig, now time.Time) time.Duration {
+	if !e.WrittenAt.IsZero() {
+		return now.Sub(e.WrittenAt)
+	}
+	f, ok := lookupFamily(e.Key)
+	if !ok || e.ExpiresAt.IsZero() {
+		return 0
+	}
+	return f.ttl(ttl) - e.ExpiresAt.Sub(now)
+}
+
+type FamilyStats struct {
+	Family  string
+	Entries int
+	Hits    int64
+	Misses  int64
+}
+
+func (s FamilyStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// Manager inspects and maintains the entries of the configured cache backend.
+type Manager struct {
+	store rawStore
+	ttl   config.CacheTTLConfig
+}
+
+func NewManager(ctx context.Context, cfg config.CacheConfig) (*Manager, error) {
+	var s rawStore
+	switch cfg.Type {
+	case config.RedisCache:
+		c, err := NewRedisCache(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		s = c.(*goCache).store
+	case config.NoCache:
+		return nil, errors.New("cache is disabled in the configuration")
+	default:
+		fs, err := openFileStore(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		s = fs
+	}
+	return &Manager{store: s, ttl: cfg.TTL}, nil
+}
+
+// Close persists the changes made through the manager.
+func (m *Manager) Close() error {
+	return m.store.Save()
+}
+
+func (m *Manager) Stats(ctx context.Context) ([]FamilyStats, error) {
+	keys, err := m.entryKeys(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, key := range keys {
+		counts[keyFamily(key)]++
+	}
+
+	stats := make([]FamilyStats, 0, len(families))
+	for _, f := range families {
+		s := FamilyStats{Family: f.name, Entries: counts[f.name]}
+		if s.Hits, err = m.store.Counter(ctx, fmt.Sprintf(hitsKey, f.name)); err != nil {
+			return nil, err
+		}
+		if s.Misses, err = m.store.Counter(ctx, fmt.Sprintf(missesKey, f.name)); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// List returns the entries whose key matches pattern, without their values.
+// An empty pattern matches every entry.
+func (m *Manager) List(ctx context.Context, pattern string) ([]Entry, error) {
+	keys, err := m.entryKeys(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(keys))
+	for _, key := range keys {
+		_, ttl, err := m.store.GetWithTTL(ctx, key)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, Entry{Key: key, Family: keyFamily(key), WrittenAt: m.writtenAt(ctx, key), ExpiresAt: expiresAt(ttl)})
+	}
+	return entries, nil
+}
+
+// Get returns the entry stored under key with its decoded value.
+func (m *Manager) Get(ctx context.Context, key string) (Entry, error) {
+	f, ok := lookupFamily(key)
+	if !ok {
+		return Entry{}, fmt.Errorf("unknown cache key family for %s", key)
+	}
+
+	raw, ttl, err := m.store.GetWithTTL(ctx, key)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	value := f.newValue()
+	if err := msgpack.Unmarshal(rawBytes(raw), value); err != nil {
+		return Entry{}, fmt.Errorf("failed to decode cache entry %s: %w", key, err)
+	}
+
+	return Entry{Key: key, Family: f.name, WrittenAt: m.writtenAt(ctx, key), ExpiresAt: expiresAt(ttl), Value: value}, nil
+}
+
+// Purge deletes the entries matching pattern that are older than olderThan.
+// A zero olderThan purges regardless of age.
+func (m *Manager) Purge(ctx context.Context, pattern string, olderThan time.Duration) (int, error) {
+	entries, err := m.List(ctx, pattern)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	purged := 0
+	for _, entry := range entries {
+		if olderThan > 0 && entry.Age(m.ttl, now) < olderThan {
+			continue
+		}
+		if err := m.store.Delete(ctx, entry.Key); err != nil {
+			return purged, err
+		}
+		if err := m.store.Delete(ctx, writtenKey(entry.Key)); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// Export writes the entries matching pattern to w as a JSON array.
+func (m *Manager) Export(ctx context.Context, w io.Writer, pattern string) (int, error) {
+	keys, err := m.entryKeys(ctx, pattern)
+	if err != nil {
+		return 0, err
+	}
+
+	entries := make([]Entry, 0, len(keys))
+	for _, key := range keys {
+		entry, err := m.Get(ctx, key)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(entries); err != nil {
+		return 0, fmt.Errorf("failed to encode cache entries: %w", err)
+	}
+	return len(entries), nil
+}
+
+// Import reads entries written by Export. Entries that already expired are
+// skipped; entries without expiration get the TTL of their family.
+func (m *Manager) Import(ctx context.Context, r io.Reader) (int, error) {
+	var rawEntries []struct {
+		Key       string          `json:"key"`
+		WrittenAt time.Time       `json:"written_at"`
+		ExpiresAt time.Time       `json:"expires_at"`
+		Value     json.RawMessage `json:"value"`
+	}
+	if err := json.NewDecoder(r).Decode(&rawEntries); err != nil {
+		return 0, fmt.Errorf("failed to decode cache entries: %w", err)
+	}
+
+	now := time.Now()
+	imported := 0
+	for _, raw := range rawEntries {
+		f, ok := lookupFamily(raw.Key)
+		if !ok {
+			return imported, fmt.Errorf("unknown cache key family for %s", raw.Key)
+		}
+
+		expiration := f.ttl(m.ttl)
+		if !raw.ExpiresAt.IsZero() {
+			expiration = raw.ExpiresAt.Sub(now)
+			if expiration <= 0 {
+				continue
+			}
+		}
+
+		value := f.newValue()
+		if err := json.Unmarshal(raw.Value, value); err != nil {
+			return imported, fmt.Errorf("failed to decode value of %s: %w", raw.Key, err)
+		}
+		bytes, err := msgpack.Marshal(value)
+		if err != nil {
+			return imported, err
+		}
+		if err := m.store.Set(ctx, raw.Key, bytes, store.WithExpiration(expiration)); err != nil {
+			return imported, err
+		}
+		if !raw.WrittenAt.IsZero() {
+			if err := m.store.Set(ctx, writtenKey(raw.Key), formatWrittenAt(raw.WrittenAt), store.WithExpiration(expiration)); err != nil {
+				return imported, err
+			}
+		}
+		imported++
+	}
+	return imported, nil
+}
+
+// writtenAt returns the write time recorded for key, or the zero time for
+// entries written before it was recorded.
+func (m *Manager) writtenAt(ctx context.Context, key string) time.Time {
+	raw, err := m.store.Get(ctx, writtenKey(key))
+	if err != nil {
+		return time.Time{}
+	}
+	writtenAt, err := time.Parse(time.RFC3339Nano, string(rawBytes(raw)))
+	if err != nil {
+		return time.Time{}
+	}
+	return writtenAt
+}
+
+// entryKeys lists the sorted data keys matching pattern, leaving out the
+// statistics counters and write times.
+func (m *Manager) entryKeys(ctx context.Context, pattern string) ([]string, error) {
+	keys, err := m.store.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	match := globRegexp(pattern)
+	matching := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if strings.HasPrefix(key, statsPrefix) || strings.HasPrefix(key, writtenPrefix) || !match.MatchString(key) {
+			continue
+		}
+		matching = append(matching, key)
+	}
+	sort.Strings(matching)
+	return matching, nil
+}
+
+// globRegexp converts a glob pattern where '*' matches any run of characters
+// and '?' a single one. Unlike path.Match, '*' also spans the '/' that may
+// appear in search queries.
+func globRegexp(pattern string) *regexp.Regexp {
+	if pattern == "" {
+		pattern = "*"
+	}
+	quoted := regexp.QuoteMeta(pattern)
+	quoted = strings.ReplaceAll(quoted, `\*`, ".*")
+	quoted = strings.ReplaceAll(quoted, `\?`, ".")
+	return regexp.MustCompile("^" + quoted + "$")
+}
+
+func writtenKey(key string) string {
+	return writtenPrefix + key
+}
+
+func formatWrittenAt(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func expiresAt(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+func rawBytes(raw any) []byte {
+	switch v := raw.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	}
+	return nil
+}
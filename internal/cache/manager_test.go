@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/pkg/config"
+)
+
+func TestKeyFamily(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"search:movie:en-us:the matrix:year:1999:page:1", FamilyMovieSearch},
+		{"search:tvshow:en-us:the office:year:0:page:1", FamilyTvShowSearch},
+		{"movie:en-us:603", FamilyMovie},
+		{"movie:details:en-us:603", FamilyMovieDetails},
+		{"tvshow:en-us:2316", FamilyTvShow},
+		{"tvshow:details:en-us:2316", FamilyTvShowDetails},
+		{"tvshow:en-us:2316:season:2", FamilySeason},
+		{"tvshow:en-us:2316:season:2:episode:5", FamilyEpisode},
+		{"something:else", FamilyUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := keyFamily(tt.key); got != tt.want {
+				t.Errorf("keyFamily(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManagerExportImport(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.CacheConfig{
+		Type: config.FileCache,
+		File: filepath.Join(t.TempDir(), "cache.gob"),
+		TTL: config.CacheTTLConfig{
+			Search: time.Hour,
+			Movie:  24 * time.Hour,
+			TvShow: 24 * time.Hour,
+			Season: 24 * time.Hour,
+		},
+	}
+
+	fs, err := openFileStore(cfg.File)
+	if err != nil {
+		t.Fatalf("openFileStore() error = %v", err)
+	}
+	c := newStoreCache(fs, cfg.TTL).WithLanguage("en-US")
+	movie := mediadata.Movie{ID: "603", Title: "The Matrix", Year: "1999"}
+	if err := c.SetMovie(ctx, movie.ID, movie); err != nil {
+		t.Fatalf("SetMovie() error = %v", err)
+	}
+	_, _ = c.GetMovie(ctx, movie.ID)
+	_, _ = c.GetMovie(ctx, "404")
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	manager, err := NewManager(ctx, cfg)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	stats, err := manager.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	for _, s := range stats {
+		if s.Family == FamilyMovie && (s.Entries != 1 || s.Hits != 1 || s.Misses != 1) {
+			t.Errorf("movie stats = %+v, want 1 entry, 1 hit, 1 miss", s)
+		}
+	}
+
+	var buf bytes.Buffer
+	if n, err := manager.Export(ctx, &buf, "movie:*"); err != nil || n != 1 {
+		t.Fatalf("Export() = %d, %v, want 1 entry", n, err)
+	}
+
+	if n, err := manager.Purge(ctx, "*", 0); err != nil || n != 1 {
+		t.Fatalf("Purge() = %d, %v, want 1 entry", n, err)
+	}
+
+	if n, err := manager.Import(ctx, &buf); err != nil || n != 1 {
+		t.Fatalf("Import() = %d, %v, want 1 entry", n, err)
+	}
+
+	entry, err := manager.Get(ctx, "movie:en-us:603")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got := *entry.Value.(*mediadata.Movie); got != movie {
+		t.Errorf("Get() value = %+v, want %+v", got, movie)
+	}
+}
+
+func TestManagerPurgeNegativeEntryAge(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.CacheConfig{
+		Type: config.FileCache,
+		File: filepath.Join(t.TempDir(), "cache.gob"),
+		TTL: config.CacheTTLConfig{
+			Search:   24 * time.Hour,
+			Negative: 10 * time.Minute,
+		},
+	}
+
+	fs, err := openFileStore(cfg.File)
+	if err != nil {
+		t.Fatalf("openFileStore() error = %v", err)
+	}
+	c := newStoreCache(fs, cfg.TTL).WithLanguage("en-US")
+	if err := c.SetMovieSearch(ctx, "no such movie", 0, 1, mediadata.MovieResults{}); err != nil {
+		t.Fatalf("SetMovieSearch() error = %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	manager, err := NewManager(ctx, cfg)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	entries, err := manager.List(ctx, "search:*")
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("List() = %v, %v, want 1 entry", entries, err)
+	}
+	if age := entries[0].Age(cfg.TTL, time.Now()); age < 0 || age > time.Minute {
+		t.Errorf("Age() = %v, want the time since the entry was written", age)
+	}
+
+	// The entry was just written, so it is not older than an hour even though
+	// a search entry with a normal TTL and the same expiration would be.
+	if n, err := manager.Purge(ctx, "*", time.Hour); err != nil || n != 0 {
+		t.Fatalf("Purge(1h) = %d, %v, want no entry", n, err)
+	}
+	if n, err := manager.Purge(ctx, "*", 0); err != nil || n != 1 {
+		t.Fatalf("Purge() = %d, %v, want 1 entry", n, err)
+	}
+	if keys, err := manager.store.Keys(ctx); err != nil || len(keys) != 0 {
+		t.Errorf("Keys() after purge = %v, %v, want none", keys, err)
+	}
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nouuu/gonamer/internal/mediadata"
+)
+
+var ErrCacheDisabled = errors.New("cache disabled")
+
+// noCache is used when caching is turned off or its backend is unreachable.
+// Every lookup is a miss and every write is dropped.
+type noCache struct{}
+
+func NewNoCache() Cache {
+	return noCache{}
+}
+
+func (noCache) SetMovieSearch(context.Context, string, int, int, mediadata.MovieResults) error {
+	return nil
+}
+
+func (noCache) GetMovieSearch(context.Context, string, int, int) (mediadata.MovieResults, error) {
+	return mediadata.MovieResults{}, ErrCacheDisabled
+}
+
+func (noCache) SetTvShowSearch(context.Context, string, int, int, mediadata.TvShowResults) error {
+	return nil
+}
+
+func (noCache) GetTvShowSearch(context.Context, string, int, int) (mediadata.TvShowResults, error) {
+	return mediadata.TvShowResults{}, ErrCacheDisabled
+}
+
+func (noCache) SetMovie(context.Context, string, mediadata.Movie) error {
+	return nil
+}
+
+func (noCache) GetMovie(context.Context, string) (mediadata.Movie, error) {
+	return mediadata.Movie{}, ErrCacheDisabled
+}
+
+func (noCache) SetMovieDetails(context.Context, string, mediadata.MovieDetails) error {
+	return nil
+}
+
+func (noCache) GetMovieDetails(context.Context, string) (mediadata.MovieDetails, error) {
+	return mediadata.MovieDetails{}, ErrCacheDisabled
+}
+
+func (noCache) SetTvShow(context.Context, string, mediadata.TvShow) error {
+	return nil
+}
+
+func (noCache) GetTvShow(context.Context, string) (mediadata.TvShow, error) {
+	return mediadata.TvShow{}, ErrCacheDisabled
+}
+
+func (noCache) SetTvShowDetails(context.Context, string, mediadata.TvShowDetails) error {
+	return nil
+}
+
+func (noCache) GetTvShowDetails(context.Context, string) (mediadata.TvShowDetails, error) {
+	return mediadata.TvShowDetails{}, ErrCacheDisabled
+}
+
+func (noCache) SetSeasonEpisodes(context.Context, string, int, []mediadata.Episode) error {
+	return nil
+}
+
+func (noCache) GetSeasonEpisodes(context.Context, string, int) ([]mediadata.Episode, error) {
+	return nil, ErrCacheDisabled
+}
+
+func (noCache) SetEpisode(context.Context, string, int, int, mediadata.Episode) error {
+	return nil
+}
+
+func (noCache) GetEpisode(context.Context, string, int, int) (mediadata.Episode, error) {
+	return mediadata.Episode{}, ErrCacheDisabled
+}
+
+func (c noCache) WithLanguage(string) Cache {
+	return c
+}
+
+func (noCache) Close() error {
+	return nil
+}
+
+func (noCache) SetMovieFind(context.Context, mediadata.ExternalSource, string, mediadata.Movie) error {
+	return nil
+}
+
+func (noCache) GetMovieFind(context.Context, mediadata.ExternalSource, string) (mediadata.Movie, error) {
+	return mediadata.Movie{}, ErrCacheDisabled
+}
+
+func (noCache) SetTvShowFind(context.Context, mediadata.ExternalSource, string, mediadata.TvShow) error {
+	return nil
+}
+
+func (noCache) GetTvShowFind(context.Context, mediadata.ExternalSource, string) (mediadata.TvShow, error) {
+	return mediadata.TvShow{}, ErrCacheDisabled
+}
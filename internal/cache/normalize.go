@@ -0,0 +1,9 @@
+package cache
+
+import "github.com/nouuu/gonamer/pkg/normalize"
+
+// normalizeQuery folds a search query so that "The  Office", "the office"
+// and "thé office" share the same cache entry.
+func normalizeQuery(query string) string {
+	return normalize.Fold(query)
+}
@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eko/gocache/lib/v4/store"
+	"github.com/nouuu/gonamer/pkg/config"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	RedisType        = "redis"
+	redisPingTimeout = 3 * time.Second
+)
+
+// NewRedisCache creates a cache shared through a Redis server, so that several
+// GoNamer instances reuse each other's TMDB lookups.
+func NewRedisCache(ctx context.Context, cfg config.CacheConfig) (Cache, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Address,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	pingCtx, cancel := context.WithTimeout(ctx, redisPingTimeout)
+	defer cancel()
+	if err := client.Ping(pingCtx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", cfg.Redis.Address, err)
+	}
+
+	return newStoreCache(newRedisStore(client, cfg.Redis.Prefix), cfg.TTL), nil
+}
+
+// redisStore is a gocache store backed by go-redis. Every key is namespaced
+// with prefix so the database can be shared with other applications.
+type redisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func newRedisStore(client *redis.Client, prefix string) *redisStore {
+	return &redisStore{client: client, prefix: prefix}
+}
+
+func (s *redisStore) key(key any) string {
+	return s.prefix + key.(string)
+}
+
+func (s *redisStore) Get(ctx context.Context, key any) (any, error) {
+	value, err := s.client.Get(ctx, s.key(key)).Bytes()
+	if err != nil {
+		return nil, store.NotFoundWithCause(err)
+	}
+	return value, nil
+}
+
+func (s *redisStore) GetWithTTL(ctx context.Context, key any) (any, time.Duration, error) {
+	value, err := s.Get(ctx, key)
+	if err != nil {
+		return nil, 0, err
+	}
+	ttl, err := s.client.TTL(ctx, s.key(key)).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	return value, ttl, nil
+}
+
+func (s *redisStore) Set(ctx context.Context, key any, value any, options ...store.Option) error {
+	opts := store.ApplyOptions(options...)
+	return s.client.Set(ctx, s.key(key), value, opts.Expiration).Err()
+}
+
+func (s *redisStore) Delete(ctx context.Context, key any) error {
+	return s.client.Del(ctx, s.key(key)).Err()
+}
+
+func (s *redisStore) Invalidate(_ context.Context, _ ...store.InvalidateOption) error {
+	return errors.New("tag invalidation is not supported by the redis store")
+}
+
+// Clear removes every key under the store prefix, leaving the rest of the
+// database untouched.
+func (s *redisStore) Clear(ctx context.Context) error {
+	iter := s.client.Scan(ctx, 0, s.prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		if err := s.client.Del(ctx, iter.Val()).Err(); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+func (s *redisStore) Keys(ctx context.Context) ([]string, error) {
+	var keys []string
+	iter := s.client.Scan(ctx, 0, s.prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, strings.TrimPrefix(iter.Val(), s.prefix))
+	}
+	return keys, iter.Err()
+}
+
+func (s *redisStore) Incr(ctx context.Context, key string) error {
+	return s.client.Incr(ctx, s.key(key)).Err()
+}
+
+func (s *redisStore) Counter(ctx context.Context, key string) (int64, error) {
+	count, err := s.client.Get(ctx, s.key(key)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return count, err
+}
+
+// Save is a no-op: Redis persists entries on its own.
+func (s *redisStore) Save() error {
+	return nil
+}
+
+func (s *redisStore) GetType() string {
+	return RedisType
+}
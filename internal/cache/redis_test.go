@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/pkg/config"
+)
+
+func newTestRedisConfig(addr string) config.CacheConfig {
+	return config.CacheConfig{
+		Type: config.RedisCache,
+		TTL: config.CacheTTLConfig{
+			Search:   time.Hour,
+			Movie:    24 * time.Hour,
+			TvShow:   24 * time.Hour,
+			Season:   24 * time.Hour,
+			Negative: 2 * time.Hour,
+		},
+		Redis: config.RedisConfig{
+			Address: addr,
+			Prefix:  "test:",
+		},
+	}
+}
+
+func TestRedisCache(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+
+	c, err := NewRedisCache(ctx, newTestRedisConfig(server.Addr()))
+	if err != nil {
+		t.Fatalf("NewRedisCache() error = %v", err)
+	}
+
+	movie := mediadata.Movie{ID: "603", Title: "The Matrix", Year: "1999"}
+	if err := c.SetMovie(ctx, movie.ID, movie); err != nil {
+		t.Fatalf("SetMovie() error = %v", err)
+	}
+
+	got, err := c.GetMovie(ctx, movie.ID)
+	if err != nil {
+		t.Fatalf("GetMovie() error = %v", err)
+	}
+	if got != movie {
+		t.Errorf("GetMovie() = %+v, want %+v", got, movie)
+	}
+
+	if !server.Exists("test:movie:default:603") {
+		t.Errorf("expected key to be stored with prefix, got keys %v", server.Keys())
+	}
+
+	results := mediadata.MovieResults{Movies: []mediadata.Movie{movie}, Totals: 1}
+	if err := c.SetMovieSearch(ctx, "The  Mätrix", 1999, 1, results); err != nil {
+		t.Fatalf("SetMovieSearch() error = %v", err)
+	}
+	if ttl := server.TTL("test:search:movie:default:the matrix:year:1999:page:1"); ttl != time.Hour {
+		t.Errorf("search TTL = %v, want %v, keys %v", ttl, time.Hour, server.Keys())
+	}
+	if _, err := c.GetMovieSearch(ctx, "the matrix", 1999, 1); err != nil {
+		t.Errorf("GetMovieSearch() error = %v, normalized query should hit", err)
+	}
+	if _, err := c.WithLanguage("fr-FR").GetMovieSearch(ctx, "the matrix", 1999, 1); err == nil {
+		t.Error("GetMovieSearch() expected a miss in another language")
+	}
+
+	if err := c.SetMovieSearch(ctx, "no such movie", 0, 1, mediadata.MovieResults{}); err != nil {
+		t.Fatalf("SetMovieSearch() error = %v", err)
+	}
+	if ttl := server.TTL("test:search:movie:default:no such movie:year:0:page:1"); ttl != 2*time.Hour {
+		t.Errorf("negative search TTL = %v, want %v", ttl, 2*time.Hour)
+	}
+
+	server.FastForward(90 * time.Minute)
+	if _, err := c.GetMovieSearch(ctx, "the matrix", 1999, 1); err == nil {
+		t.Error("GetMovieSearch() expected expired entry to be a miss")
+	}
+	if _, err := c.GetMovieSearch(ctx, "no such movie", 0, 1); err != nil {
+		t.Errorf("GetMovieSearch() error = %v, negative entry should still be cached", err)
+	}
+	if _, err := c.GetMovie(ctx, movie.ID); err != nil {
+		t.Errorf("GetMovie() error = %v, movie entry should outlive search entry", err)
+	}
+}
+
+func TestNewFallsBackWhenRedisUnreachable(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	addr := server.Addr()
+	server.Close()
+
+	c, err := New(ctx, newTestRedisConfig(addr))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, ok := c.(noCache); !ok {
+		t.Fatalf("New() = %T, want noCache", c)
+	}
+
+	if err := c.SetMovie(ctx, "603", mediadata.Movie{ID: "603"}); err != nil {
+		t.Errorf("SetMovie() error = %v", err)
+	}
+	if _, err := c.GetMovie(ctx, "603"); err == nil {
+		t.Error("GetMovie() expected a miss from disabled cache")
+	}
+}
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/eko/gocache/lib/v4/store"
+	gocache_store "github.com/eko/gocache/store/go_cache/v4"
+	gocache "github.com/patrickmn/go-cache"
+)
+
+// rawStore is the backend behind a Cache. On top of the gocache store it gives
+// the management commands access to keys, expirations and usage counters.
+type rawStore interface {
+	store.StoreInterface
+	Keys(ctx context.Context) ([]string, error)
+	Incr(ctx context.Context, key string) error
+	Counter(ctx context.Context, key string) (int64, error)
+	Save() error
+}
+
+// fileStore keeps entries in memory with go-cache and persists them to a gob file.
+type fileStore struct {
+	*gocache_store.GoCacheStore
+	client *gocache.Cache
+	path   string
+	mu     sync.Mutex
+}
+
+func openFileStore(path string) (*fileStore, error) {
+	client := gocache.New(5*time.Minute, 10*time.Minute)
+
+	if err := client.LoadFile(path); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to load cache file: %w", err)
+		}
+	}
+
+	return &fileStore{
+		GoCacheStore: gocache_store.NewGoCache(client),
+		client:       client,
+		path:         path,
+	}, nil
+}
+
+func (s *fileStore) Keys(_ context.Context) ([]string, error) {
+	items := s.client.Items()
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (s *fileStore) Incr(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.client.Increment(key, 1); err != nil {
+		s.client.Set(key, int64(1), gocache.NoExpiration)
+	}
+	return nil
+}
+
+func (s *fileStore) Counter(_ context.Context, key string) (int64, error) {
+	value, found := s.client.Get(key)
+	if !found {
+		return 0, nil
+	}
+	count, ok := value.(int64)
+	if !ok {
+		return 0, fmt.Errorf("cache key %s is not a counter", key)
+	}
+	return count, nil
+}
+
+func (s *fileStore) Save() error {
+	return s.client.SaveFile(s.path)
+}
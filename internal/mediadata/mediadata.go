@@ -3,11 +3,16 @@ package mediadata
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 )
 
+// ErrNeedsOnlineLookup is returned by clients running offline when the
+// requested data is not available in the cache.
+var ErrNeedsOnlineLookup = errors.New("needs online lookup")
+
 type Status string
 
 const (
@@ -32,6 +37,14 @@ type Studio struct {
 	Name string `json:"name"`
 }
 
+// Collection is the franchise a movie belongs to, e.g. "The Lord of the Rings
+// Collection".
+type Collection struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	PosterURL string `json:"poster_url"`
+}
+
 type Movie struct {
 	ID          string  `json:"id"`
 	Title       string  `json:"title"`
@@ -41,14 +54,32 @@ type Movie struct {
 	PosterURL   string  `json:"poster_url"`
 	Rating      float32 `json:"rating"`
 	RatingCount int64   `json:"rating_count"`
+	// OriginalLanguage is an ISO 639-1 code, e.g. "en".
+	OriginalTitle    string `json:"original_title"`
+	OriginalLanguage string `json:"original_language"`
+	// TitleLanguage is the language of Title when a search found the movie
+	// in another language than the client one, empty otherwise.
+	TitleLanguage string `json:"title_language,omitempty"`
 }
 
 type MovieDetails struct {
 	Movie
-	Runtime int      `json:"runtime"`
-	Genres  []Genre  `json:"genres"`
-	Cast    []Person `json:"cast"`
-	Studio  []Studio `json:"studio"`
+	ImdbID        string   `json:"imdb_id"`
+	WikidataID    string   `json:"wikidata_id"`
+	Certification string   `json:"certification"`
+	Runtime       int      `json:"runtime"`
+	Genres        []Genre  `json:"genres"`
+	Cast          []Person `json:"cast"`
+	Studio        []Studio `json:"studio"`
+	Keywords      []string `json:"keywords"`
+	// Collection is empty for movies that aren't part of one.
+	Collection Collection `json:"collection"`
+	// OriginCountry and ProductionCountries hold ISO 3166-1 codes.
+	OriginCountry       []string `json:"origin_country"`
+	ProductionCountries []string `json:"production_countries"`
+	// AlternativeTitles are the other titles of the movie across countries,
+	// without duplicates.
+	AlternativeTitles []string `json:"alternative_titles"`
 }
 
 type MovieResults struct {
@@ -74,6 +105,7 @@ type Episode struct {
 	StillURL      string  `json:"still_url"`
 	VoteAverage   float32 `json:"vote_average"`
 	VoteCount     int64   `json:"vote_count"`
+	Runtime       int     `json:"runtime"`
 }
 
 type TvShow struct {
@@ -85,19 +117,37 @@ type TvShow struct {
 	PosterURL   string  `json:"poster_url"`
 	Rating      float32 `json:"rating"`
 	RatingCount int64   `json:"rating_count"`
+	// OriginalLanguage is an ISO 639-1 code, e.g. "en", and OriginCountry
+	// holds ISO 3166-1 codes.
+	OriginalTitle    string   `json:"original_title"`
+	OriginalLanguage string   `json:"original_language"`
+	OriginCountry    []string `json:"origin_country"`
+	// TitleLanguage is Movie.TitleLanguage for tv shows.
+	TitleLanguage string `json:"title_language,omitempty"`
 }
 
 type TvShowDetails struct {
 	TvShow
-	SeasonCount  int      `json:"season_count"`
-	EpisodeCount int      `json:"episode_count"`
-	LastEpisode  Episode  `json:"last_episode"`
-	NextEpisode  Episode  `json:"next_episode"`
-	Status       Status   `json:"status"`
-	Seasons      []Season `json:"seasons"`
-	Genres       []Genre  `json:"genres"`
-	Cast         []Person `json:"cast"`
-	Studio       []Studio `json:"studio"`
+	ImdbID         string   `json:"imdb_id"`
+	TvdbID         string   `json:"tvdb_id"`
+	WikidataID     string   `json:"wikidata_id"`
+	Certification  string   `json:"certification"`
+	EpisodeRuntime int      `json:"episode_runtime"`
+	SeasonCount    int      `json:"season_count"`
+	EpisodeCount   int      `json:"episode_count"`
+	LastEpisode    Episode  `json:"last_episode"`
+	NextEpisode    Episode  `json:"next_episode"`
+	Status         Status   `json:"status"`
+	Seasons        []Season `json:"seasons"`
+	Genres         []Genre  `json:"genres"`
+	Cast           []Person `json:"cast"`
+	Studio         []Studio `json:"studio"`
+	Keywords       []string `json:"keywords"`
+
+	ProductionCountries []string `json:"production_countries"`
+	// AlternativeTitles are the other titles of the show across countries,
+	// without duplicates.
+	AlternativeTitles []string `json:"alternative_titles"`
 }
 
 type TvShowResults struct {
@@ -106,17 +156,34 @@ type TvShowResults struct {
 	ResultsPerPage int64    `json:"results_per_page"`
 }
 
+// ExternalSource is a database whose IDs TMDB can find items by.
+type ExternalSource string
+
+const (
+	SourceIMDb ExternalSource = "imdb_id"
+	SourceTVDB ExternalSource = "tvdb_id"
+)
+
+// ErrNotFound is returned when no item has the requested external ID.
+var ErrNotFound = errors.New("not found")
+
 type MovieClient interface {
 	SearchMovie(ctx context.Context, query string, year int, page int) (MovieResults, error)
 	GetMovie(ctx context.Context, id string) (Movie, error)
 	GetMovieDetails(ctx context.Context, id string) (MovieDetails, error)
+	// FindMovie returns the movie having id in the source database.
+	FindMovie(ctx context.Context, source ExternalSource, id string) (Movie, error)
 }
 
 type TvShowClient interface {
 	SearchTvShow(ctx context.Context, query string, year int, page int) (TvShowResults, error)
 	GetTvShow(ctx context.Context, id string) (TvShow, error)
+	// FindTvShow returns the show having id in the source database, or the
+	// show of the episode having it.
+	FindTvShow(ctx context.Context, source ExternalSource, id string) (TvShow, error)
 	GetTvShowDetails(ctx context.Context, id string) (TvShowDetails, error)
 	GetEpisode(ctx context.Context, id string, seasonNumber int, episodeNumber int) (Episode, error)
+	GetSeasonEpisodes(ctx context.Context, id string, seasonNumber int) ([]Episode, error)
 }
 
 func ShowMovieResults(movies MovieResults) {
@@ -174,3 +241,13 @@ func marshalTvShow(tvShow TvShow) (string, error) {
 	}
 	return string(mJson), nil
 }*/
+
+// FindEpisode returns the episode numbered episodeNumber in a season listing.
+func FindEpisode(episodes []Episode, episodeNumber int) (Episode, bool) {
+	for _, episode := range episodes {
+		if episode.EpisodeNumber == episodeNumber {
+			return episode, true
+		}
+	}
+	return Episode{}, false
+}
@@ -1,7 +1,10 @@
 package tmdb
 
 import (
+	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/cyruzin/golang-tmdb"
 	"github.com/nouuu/gonamer/internal/cache"
@@ -18,8 +21,12 @@ type AllOpts struct {
 }
 
 type Opts struct {
-	Lang  string
-	Adult bool
+	// Lang is the language of the titles returned, SearchLangs the ones
+	// searches are tried in, Lang alone when empty.
+	Lang        string
+	SearchLangs []string
+	Adult       bool
+	Offline     bool
 }
 
 func WithLang(lang string) OptFunc {
@@ -28,12 +35,28 @@ func WithLang(lang string) OptFunc {
 	}
 }
 
+// WithSearchLangs sets the languages searches are tried in, in order, until
+// one finds results.
+func WithSearchLangs(langs ...string) OptFunc {
+	return func(opts *Opts) {
+		opts.SearchLangs = langs
+	}
+}
+
 func WithAdult(adult bool) OptFunc {
 	return func(opts *Opts) {
 		opts.Adult = adult
 	}
 }
 
+// WithOffline makes the client answer only from the cache. Cache misses are
+// reported as mediadata.ErrNeedsOnlineLookup instead of reaching TMDB.
+func WithOffline(offline bool) OptFunc {
+	return func(opts *Opts) {
+		opts.Offline = offline
+	}
+}
+
 func defaultOpts(apiKey string) AllOpts {
 	return AllOpts{
 		APIKey: apiKey,
@@ -50,6 +73,40 @@ type tmdbClient struct {
 	cache  cache.Cache
 }
 
+func (t *tmdbClient) offlineMiss(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", mediadata.ErrNeedsOnlineLookup, fmt.Sprintf(format, args...))
+}
+
+// region returns the country part of the client language ("fr-FR" → "FR"),
+// used to pick certifications.
+func (o Opts) region() string {
+	if _, region, ok := strings.Cut(o.Lang, "-"); ok {
+		return strings.ToUpper(region)
+	}
+	return strings.ToUpper(o.Lang)
+}
+
+// pickCertification returns the rating of the preferred country, falling back
+// to the US one and then to the first non-empty rating.
+func pickCertification(ratings map[string]string, region string) string {
+	for _, country := range []string{region, "US"} {
+		if r := ratings[country]; r != "" {
+			return r
+		}
+	}
+	countries := make([]string, 0, len(ratings))
+	for country, r := range ratings {
+		if r != "" {
+			countries = append(countries, country)
+		}
+	}
+	if len(countries) == 0 {
+		return ""
+	}
+	sort.Strings(countries)
+	return ratings[countries[0]]
+}
+
 func cfgMap(opts AllOpts, args ...map[string]string) map[string]string {
 	cfg := map[string]string{
 		"language":      opts.Lang,
@@ -93,3 +150,31 @@ func buildStudio(studios []struct {
 	}
 	return s
 }
+
+// buildCountries returns the ISO 3166-1 codes of the countries.
+func buildCountries(countries []struct {
+	Iso3166_1 string `json:"iso_3166_1"`
+	Name      string `json:"name"`
+}) []string {
+	codes := make([]string, 0, len(countries))
+	for _, country := range countries {
+		codes = append(codes, country.Iso3166_1)
+	}
+	return codes
+}
+
+// uniqueTitles returns the alternative titles without the ones repeated
+// across countries or equal to the title or the original title.
+func uniqueTitles(title, originalTitle string, titles []string) []string {
+	seen := map[string]bool{strings.ToLower(title): true, strings.ToLower(originalTitle): true}
+	unique := make([]string, 0, len(titles))
+	for _, t := range titles {
+		key := strings.ToLower(strings.TrimSpace(t))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, strings.TrimSpace(t))
+	}
+	return unique
+}
@@ -0,0 +1,86 @@
+package tmdb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/cyruzin/golang-tmdb"
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/pkg/logger"
+)
+
+func (t *tmdbClient) FindMovie(ctx context.Context, source mediadata.ExternalSource, id string) (mediadata.Movie, error) {
+	if movie, err := t.cache.GetMovieFind(ctx, source, id); err == nil {
+		return movie, nil
+	}
+	if t.opts.Offline {
+		return mediadata.Movie{}, t.offlineMiss("movie %s %s", source, id)
+	}
+	found, err := t.client.GetFindByID(id, cfgMap(t.opts, map[string]string{"external_source": string(source)}))
+	if err != nil {
+		return mediadata.Movie{}, err
+	}
+	if len(found.MovieResults) == 0 {
+		return mediadata.Movie{}, fmt.Errorf("movie %s %s: %w", source, id, mediadata.ErrNotFound)
+	}
+	result := found.MovieResults[0]
+	movie := buildMovie(&tmdb.MovieDetails{
+		ID:               result.ID,
+		Title:            result.Title,
+		Overview:         result.Overview,
+		ReleaseDate:      result.ReleaseDate,
+		PosterPath:       result.PosterPath,
+		VoteAverage:      result.VoteAverage,
+		VoteCount:        result.VoteCount,
+		OriginalTitle:    result.OriginalTitle,
+		OriginalLanguage: result.OriginalLanguage,
+	})
+	if err := t.cache.SetMovieFind(ctx, source, id, movie); err != nil {
+		logger.FromContext(ctx).With("error", err).Error("failed to cache movie find")
+	}
+	return movie, nil
+}
+
+func (t *tmdbClient) FindTvShow(ctx context.Context, source mediadata.ExternalSource, id string) (mediadata.TvShow, error) {
+	if tvShow, err := t.cache.GetTvShowFind(ctx, source, id); err == nil {
+		return tvShow, nil
+	}
+	if t.opts.Offline {
+		return mediadata.TvShow{}, t.offlineMiss("tv show %s %s", source, id)
+	}
+	found, err := t.client.GetFindByID(id, cfgMap(t.opts, map[string]string{"external_source": string(source)}))
+	if err != nil {
+		return mediadata.TvShow{}, err
+	}
+
+	var tvShow mediadata.TvShow
+	switch {
+	case len(found.TvResults) > 0:
+		result := found.TvResults[0]
+		tvShow = buildTvShow(&tmdb.TVDetails{
+			ID:               result.ID,
+			Name:             result.Name,
+			Overview:         result.Overview,
+			FirstAirDate:     result.FirstAirDate,
+			PosterPath:       result.PosterPath,
+			VoteAverage:      result.VoteAverage,
+			VoteCount:        result.VoteCount,
+			OriginalName:     result.OriginalName,
+			OriginalLanguage: result.OriginalLanguage,
+			OriginCountry:    result.OriginCountry,
+		})
+	case len(found.TvEpisodeResults) > 0:
+		// Releases often carry the IMDb ID of the episode rather than the show.
+		if tvShow, err = t.GetTvShow(ctx, strconv.FormatInt(found.TvEpisodeResults[0].ShowID, 10)); err != nil {
+			return mediadata.TvShow{}, err
+		}
+	default:
+		return mediadata.TvShow{}, fmt.Errorf("tv show %s %s: %w", source, id, mediadata.ErrNotFound)
+	}
+
+	if err := t.cache.SetTvShowFind(ctx, source, id, tvShow); err != nil {
+		logger.FromContext(ctx).With("error", err).Error("failed to cache tv show find")
+	}
+	return tvShow, nil
+}
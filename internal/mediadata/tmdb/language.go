@@ -0,0 +1,70 @@
+package tmdb
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/pkg/logger"
+)
+
+// fallbackLang is the last language episode titles are looked up in, the one
+// TMDB translations are most complete in.
+const fallbackLang = "en-US"
+
+// genericEpisodeNameRegex matches the placeholder titles TMDB gives episodes
+// not translated yet: "Episode 5", "Épisode 5", "5. Bölüm"...
+var genericEpisodeNameRegex = regexp.MustCompile(`(?i)^\s*(?:(?:episode|épisode|episodio|episódio|folge|aflevering|odcinek|bölüm|серия|jakso|avsnitt|afsnit|epizod)\s*#?\s*\d+|\d+\.?\s*(?:bölüm|folge|odcinek|серия|epizod))?\s*$`)
+
+func isGenericEpisodeName(name string) bool {
+	return genericEpisodeNameRegex.MatchString(name)
+}
+
+// searchLangs returns the languages searches are tried in.
+func (o Opts) searchLangs() []string {
+	if len(o.SearchLangs) == 0 {
+		return []string{o.Lang}
+	}
+	return o.SearchLangs
+}
+
+// episodeFallbackLangs returns the languages generic episode titles are
+// looked up in: the search languages, then English.
+func (o Opts) episodeFallbackLangs() []string {
+	var langs []string
+	for _, lang := range append(slices.Clone(o.searchLangs()), fallbackLang) {
+		if !strings.EqualFold(lang, o.Lang) && !slices.ContainsFunc(langs, func(l string) bool { return strings.EqualFold(l, lang) }) {
+			langs = append(langs, lang)
+		}
+	}
+	return langs
+}
+
+// fillGenericEpisodeNames replaces the empty or generic titles of a season by
+// the ones of the fallback languages.
+func (t *tmdbClient) fillGenericEpisodeNames(ctx context.Context, id int, seasonNumber int, episodes []mediadata.Episode) {
+	log := logger.FromContext(ctx)
+	generic := func(e mediadata.Episode) bool { return isGenericEpisodeName(e.Name) }
+	for _, lang := range t.opts.episodeFallbackLangs() {
+		if !slices.ContainsFunc(episodes, generic) {
+			return
+		}
+		season, err := t.client.GetTVSeasonDetails(id, seasonNumber, cfgMap(t.opts, map[string]string{"language": lang}))
+		if err != nil {
+			log.With("error", err, "language", lang).Warn("failed to get season episode titles")
+			continue
+		}
+		names := make(map[int]string, len(season.Episodes))
+		for _, episode := range season.Episodes {
+			names[episode.EpisodeNumber] = episode.Name
+		}
+		for i := range episodes {
+			if name := names[episodes[i].EpisodeNumber]; generic(episodes[i]) && !isGenericEpisodeName(name) {
+				log.Debugf("Using %s title '%s' for episode %d of season %d", lang, name, episodes[i].EpisodeNumber, seasonNumber)
+				episodes[i].Name = name
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package tmdb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsGenericEpisodeName(t *testing.T) {
+	tests := map[string]bool{
+		"":              true,
+		"Episode 5":     true,
+		"Épisode 12":    true,
+		"5. Bölüm":      true,
+		"Folge 3":       true,
+		"Pilot":         false,
+		"Episode Five":  false,
+		"The Episode 5": false,
+		"1984":          false,
+	}
+	for name, want := range tests {
+		if got := isGenericEpisodeName(name); got != want {
+			t.Errorf("isGenericEpisodeName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestEpisodeFallbackLangs(t *testing.T) {
+	opts := Opts{Lang: "en-US", SearchLangs: []string{"tr-TR", "fr-FR", "en-US"}}
+	if got, want := opts.episodeFallbackLangs(), []string{"tr-TR", "fr-FR"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("episodeFallbackLangs() = %v, want %v", got, want)
+	}
+	opts = Opts{Lang: "fr-FR"}
+	if got, want := opts.episodeFallbackLangs(), []string{"en-US"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("episodeFallbackLangs() = %v, want %v", got, want)
+	}
+}
@@ -3,6 +3,7 @@ package tmdb
 import (
 	"context"
 	"strconv"
+	"strings"
 
 	"github.com/cyruzin/golang-tmdb"
 	"github.com/nouuu/gonamer/internal/cache"
@@ -20,19 +21,47 @@ func NewMovieClient(APIKey string, cache cache.Cache, opts ...OptFunc) (mediadat
 	if err != nil {
 		return nil, err
 	}
-	return &tmdbClient{client: client, cache: cache, opts: o}, nil
+	return &tmdbClient{client: client, cache: cache.WithLanguage(o.Lang), opts: o}, nil
 }
 
+// SearchMovie tries the search languages in order, and returns the first
+// results. Results found in another language than the client one have their
+// TitleLanguage set, to be fetched again in the client language once picked.
 func (t *tmdbClient) SearchMovie(ctx context.Context, query string, year int, page int) (mediadata.MovieResults, error) {
-	if result, err := t.cache.GetMovieSearch(ctx, query, year, page); err == nil {
+	var results mediadata.MovieResults
+	for _, lang := range t.opts.searchLangs() {
+		var err error
+		if results, err = t.searchMovie(ctx, lang, query, year, page); err != nil {
+			return mediadata.MovieResults{}, err
+		}
+		if results.Totals > 0 {
+			if !strings.EqualFold(lang, t.opts.Lang) {
+				logger.FromContext(ctx).Debugf("Found '%s' in %s", query, lang)
+				for i := range results.Movies {
+					results.Movies[i].TitleLanguage = lang
+				}
+			}
+			return results, nil
+		}
+	}
+	return results, nil
+}
+
+func (t *tmdbClient) searchMovie(ctx context.Context, lang string, query string, year int, page int) (mediadata.MovieResults, error) {
+	scoped := t.cache.WithLanguage(lang)
+	if result, err := scoped.GetMovieSearch(ctx, query, year, page); err == nil {
 		return result, nil
 	}
+	if t.opts.Offline {
+		return mediadata.MovieResults{}, t.offlineMiss("movie search '%s'", query)
+	}
 	opts := map[string]string{
 		"page": strconv.Itoa(page),
 	}
 	if year != 0 {
 		opts["year"] = strconv.Itoa(year)
 	}
+	opts["language"] = lang
 	searchMovies, err := t.client.GetSearchMovies(query, cfgMap(t.opts, opts))
 	if err != nil {
 		return mediadata.MovieResults{}, err
@@ -43,7 +72,7 @@ func (t *tmdbClient) SearchMovie(ctx context.Context, query string, year int, pa
 		ResultsPerPage: 20,
 	}
 
-	if err := t.cache.SetMovieSearch(ctx, query, year, page, results); err != nil {
+	if err := scoped.SetMovieSearch(ctx, query, year, page, results); err != nil {
 		logger.FromContext(ctx).With("error", err).Error("failed to cache movie search results")
 	}
 
@@ -54,6 +83,9 @@ func (t *tmdbClient) GetMovie(ctx context.Context, id string) (mediadata.Movie,
 	if movie, err := t.cache.GetMovie(ctx, id); err == nil {
 		return movie, nil
 	}
+	if t.opts.Offline {
+		return mediadata.Movie{}, t.offlineMiss("movie %s", id)
+	}
 	idInt, err := strconv.Atoi(id)
 	if err != nil {
 		return mediadata.Movie{}, err
@@ -73,17 +105,21 @@ func (t *tmdbClient) GetMovieDetails(ctx context.Context, id string) (mediadata.
 	if details, err := t.cache.GetMovieDetails(ctx, id); err == nil {
 		return details, nil
 	}
+	if t.opts.Offline {
+		return mediadata.MovieDetails{}, t.offlineMiss("movie details %s", id)
+	}
 	idInt, err := strconv.Atoi(id)
 	if err != nil {
 		return mediadata.MovieDetails{}, err
 	}
 	movieDetails, err := t.client.GetMovieDetails(idInt, cfgMap(t.opts, map[string]string{
-		"append_to_response": "credits",
+		"append_to_response": "credits,release_dates,keywords,external_ids,alternative_titles",
 	}))
 	if err != nil {
 		return mediadata.MovieDetails{}, err
 	}
 	details := buildMovieDetails(movieDetails)
+	details.Certification = movieCertification(movieDetails, t.opts.region())
 	if err := t.cache.SetMovieDetails(ctx, id, details); err != nil {
 		logger.FromContext(ctx).With("error", err).Error("failed to cache movie details")
 	}
@@ -104,44 +140,100 @@ func buildMovie(movie *tmdb.MovieDetails) mediadata.Movie {
 		PosterURL:   tmdbImageBaseUrl + movie.PosterPath,
 		Rating:      movie.VoteAverage,
 		RatingCount: movie.VoteCount,
+
+		OriginalTitle:    movie.OriginalTitle,
+		OriginalLanguage: movie.OriginalLanguage,
 	}
 }
 
 func buildMovieDetails(details *tmdb.MovieDetails) mediadata.MovieDetails {
-	releaseYear := ""
-	if len(details.ReleaseDate) >= 4 {
-		releaseYear = details.ReleaseDate[:4]
-
-	}
-	return mediadata.MovieDetails{
-		Movie: mediadata.Movie{
-			ID:          strconv.FormatInt(details.ID, 10),
-			Title:       details.Title,
-			Overview:    details.Overview,
-			ReleaseDate: details.ReleaseDate,
-			Year:        releaseYear,
-			PosterURL:   tmdbImageBaseUrl + details.PosterPath,
-			Rating:      details.VoteAverage,
-			RatingCount: details.VoteCount,
-		},
-		Runtime: details.Runtime,
-		Genres:  buildGenres(details.Genres),
-		Cast:    buildMovieCast(details.Credits.Cast),
-		Studio:  buildStudio(details.ProductionCompanies),
+	movie := mediadata.MovieDetails{
+		Movie:      buildMovie(details),
+		ImdbID:     details.IMDbID,
+		Runtime:    details.Runtime,
+		Genres:     buildGenres(details.Genres),
+		Cast:       buildMovieCast(details.Credits.Cast),
+		Studio:     buildStudio(details.ProductionCompanies),
+		Keywords:   movieKeywords(details),
+		Collection: buildCollection(details.BelongsToCollection),
+
+		OriginCountry:       details.OriginCountry,
+		ProductionCountries: buildCountries(details.ProductionCountries),
+		AlternativeTitles:   movieAlternativeTitles(details),
+	}
+	if details.MovieExternalIDsAppend != nil && details.MovieExternalIDs != nil {
+		movie.WikidataID = details.MovieExternalIDs.WikiDataID
+		if movie.ImdbID == "" {
+			movie.ImdbID = details.MovieExternalIDs.IMDbID
+		}
+	}
+	return movie
+}
+
+func movieAlternativeTitles(details *tmdb.MovieDetails) []string {
+	if details.MovieAlternativeTitlesAppend == nil || details.AlternativeTitles == nil {
+		return nil
+	}
+	titles := make([]string, 0, len(details.AlternativeTitles.Titles))
+	for _, title := range details.AlternativeTitles.Titles {
+		titles = append(titles, title.Title)
+	}
+	return uniqueTitles(details.Title, details.OriginalTitle, titles)
+}
+
+func buildCollection(collection tmdb.BelongsToCollection) mediadata.Collection {
+	if collection.ID == 0 {
+		return mediadata.Collection{}
+	}
+	return mediadata.Collection{
+		ID:        strconv.FormatInt(collection.ID, 10),
+		Name:      collection.Name,
+		PosterURL: tmdbImageBaseUrl + collection.PosterPath,
+	}
+}
+
+func movieKeywords(details *tmdb.MovieDetails) []string {
+	if details.MovieKeywordsAppend == nil || details.Keywords.MovieKeywords == nil {
+		return nil
+	}
+	keywords := make([]string, 0, len(details.Keywords.MovieKeywords.Keywords))
+	for _, keyword := range details.Keywords.MovieKeywords.Keywords {
+		keywords = append(keywords, keyword.Name)
+	}
+	return keywords
+}
+
+// movieCertification picks the theatrical certification of the region from
+// the appended release dates.
+func movieCertification(details *tmdb.MovieDetails, region string) string {
+	if details.MovieReleaseDatesAppend == nil || details.ReleaseDates == nil || details.ReleaseDates.MovieReleaseDatesResults == nil {
+		return ""
+	}
+	ratings := make(map[string]string)
+	for _, country := range details.ReleaseDates.Results {
+		for _, release := range country.ReleaseDates {
+			if release.Certification != "" {
+				ratings[country.Iso3166_1] = release.Certification
+				break
+			}
+		}
 	}
+	return pickCertification(ratings, region)
 }
 
 func buildMovieFromResult(result *tmdb.SearchMoviesResults) []mediadata.Movie {
 	var movies = make([]mediadata.Movie, len(result.Results))
 	for i, movie := range result.Results {
 		movies[i] = buildMovie(&tmdb.MovieDetails{
-			ID:          movie.ID,
-			Title:       movie.Title,
-			Overview:    movie.Overview,
-			ReleaseDate: movie.ReleaseDate,
-			PosterPath:  movie.PosterPath,
-			VoteAverage: movie.VoteAverage,
-			VoteCount:   movie.VoteCount,
+			ID:               movie.ID,
+			Title:            movie.Title,
+			Overview:         movie.Overview,
+			ReleaseDate:      movie.ReleaseDate,
+			PosterPath:       movie.PosterPath,
+			VoteAverage:      movie.VoteAverage,
+			VoteCount:        movie.VoteCount,
+			OriginalTitle:    movie.OriginalTitle,
+			OriginalLanguage: movie.OriginalLanguage,
 		})
 	}
 	return movies
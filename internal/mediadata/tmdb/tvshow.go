@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/cyruzin/golang-tmdb"
 	"github.com/nouuu/gonamer/internal/cache"
@@ -21,19 +22,45 @@ func NewTvShowClient(APIKey string, cache cache.Cache, opts ...OptFunc) (mediada
 	if err != nil {
 		return nil, err
 	}
-	return &tmdbClient{client: client, cache: cache, opts: o}, nil
+	return &tmdbClient{client: client, cache: cache.WithLanguage(o.Lang), opts: o}, nil
 }
 
+// SearchTvShow is SearchMovie for tv shows.
 func (t *tmdbClient) SearchTvShow(ctx context.Context, query string, year int, page int) (mediadata.TvShowResults, error) {
-	if result, err := t.cache.GetTvShowSearch(ctx, query, year, page); err == nil {
+	var results mediadata.TvShowResults
+	for _, lang := range t.opts.searchLangs() {
+		var err error
+		if results, err = t.searchTvShow(ctx, lang, query, year, page); err != nil {
+			return mediadata.TvShowResults{}, err
+		}
+		if results.Totals > 0 {
+			if !strings.EqualFold(lang, t.opts.Lang) {
+				logger.FromContext(ctx).Debugf("Found '%s' in %s", query, lang)
+				for i := range results.TvShows {
+					results.TvShows[i].TitleLanguage = lang
+				}
+			}
+			return results, nil
+		}
+	}
+	return results, nil
+}
+
+func (t *tmdbClient) searchTvShow(ctx context.Context, lang string, query string, year int, page int) (mediadata.TvShowResults, error) {
+	scoped := t.cache.WithLanguage(lang)
+	if result, err := scoped.GetTvShowSearch(ctx, query, year, page); err == nil {
 		return result, nil
 	}
+	if t.opts.Offline {
+		return mediadata.TvShowResults{}, t.offlineMiss("tv show search '%s'", query)
+	}
 	opts := map[string]string{
 		"page": strconv.Itoa(page),
 	}
 	if year != 0 {
 		opts["year"] = strconv.Itoa(year)
 	}
+	opts["language"] = lang
 	searchTvShows, err := t.client.GetSearchTVShow(query, cfgMap(t.opts, opts))
 	if err != nil {
 		return mediadata.TvShowResults{}, err
@@ -43,7 +70,7 @@ func (t *tmdbClient) SearchTvShow(ctx context.Context, query string, year int, p
 		Totals:         searchTvShows.TotalResults,
 		ResultsPerPage: 20,
 	}
-	if err := t.cache.SetTvShowSearch(ctx, query, year, page, results); err != nil {
+	if err := scoped.SetTvShowSearch(ctx, query, year, page, results); err != nil {
 		logger.FromContext(ctx).With("error", err).Error("failed to cache tv show search results")
 	}
 	return results, nil
@@ -53,6 +80,9 @@ func (t *tmdbClient) GetTvShow(ctx context.Context, id string) (mediadata.TvShow
 	if show, err := t.cache.GetTvShow(ctx, id); err == nil {
 		return show, nil
 	}
+	if t.opts.Offline {
+		return mediadata.TvShow{}, t.offlineMiss("tv show %s", id)
+	}
 	idInt, err := strconv.Atoi(id)
 	if err != nil {
 		return mediadata.TvShow{}, err
@@ -72,17 +102,21 @@ func (t *tmdbClient) GetTvShowDetails(ctx context.Context, id string) (mediadata
 	if details, err := t.cache.GetTvShowDetails(ctx, id); err == nil {
 		return details, nil
 	}
+	if t.opts.Offline {
+		return mediadata.TvShowDetails{}, t.offlineMiss("tv show details %s", id)
+	}
 	idInt, err := strconv.Atoi(id)
 	if err != nil {
 		return mediadata.TvShowDetails{}, err
 	}
 	tvShowDetails, err := t.client.GetTVDetails(idInt, cfgMap(t.opts, map[string]string{
-		"append_to_response": "credits",
+		"append_to_response": "credits,content_ratings,external_ids,keywords,alternative_titles",
 	}))
 	if err != nil {
 		return mediadata.TvShowDetails{}, err
 	}
 	details := buildTvShowDetails(tvShowDetails)
+	details.Certification = tvShowCertification(tvShowDetails, t.opts.region())
 	if err := t.cache.SetTvShowDetails(ctx, id, details); err != nil {
 		logger.FromContext(ctx).With("error", err).Error("failed to cache tv show details")
 	}
@@ -94,24 +128,41 @@ func (t *tmdbClient) GetEpisode(ctx context.Context, id string, seasonNumber int
 		return episode, nil
 	}
 
-	idInt, err := strconv.Atoi(id)
+	episodes, err := t.GetSeasonEpisodes(ctx, id, seasonNumber)
 	if err != nil {
 		return mediadata.Episode{}, err
 	}
 
-	/*episodeDetails, err := t.client.GetTVEpisodeDetails(idInt, seasonNumber, episodeNumber, cfgMap(t.opts))
+	if episode, ok := mediadata.FindEpisode(episodes, episodeNumber); ok {
+		return episode, nil
+	}
+
+	return mediadata.Episode{}, fmt.Errorf("episode %d not found (season has %d episodes)", episodeNumber, len(episodes))
+}
+
+// GetSeasonEpisodes returns every episode of a season. The whole season is
+// fetched with a single request and cached both as a season and per episode.
+func (t *tmdbClient) GetSeasonEpisodes(ctx context.Context, id string, seasonNumber int) ([]mediadata.Episode, error) {
+	if episodes, err := t.cache.GetSeasonEpisodes(ctx, id, seasonNumber); err == nil {
+		return episodes, nil
+	}
+	if t.opts.Offline {
+		return nil, t.offlineMiss("tv show %s season %d", id, seasonNumber)
+	}
+
+	idInt, err := strconv.Atoi(id)
 	if err != nil {
-		return mediadata.Episode{}, err
-	}*/
+		return nil, err
+	}
 
 	season, err := t.client.GetTVSeasonDetails(idInt, seasonNumber, cfgMap(t.opts))
 	if err != nil {
-		return mediadata.Episode{}, err
+		return nil, err
 	}
 
 	episodes := make([]mediadata.Episode, 0, len(season.Episodes))
 
-	for _, episode := range season.Episodes {
+	for i, episode := range season.Episodes {
 		episode := buildEpisode(struct {
 			AirDate        string  `json:"air_date"`
 			EpisodeNumber  int     `json:"episode_number"`
@@ -137,30 +188,25 @@ func (t *tmdbClient) GetEpisode(ctx context.Context, id string, seasonNumber int
 			VoteAverage:    episode.VoteAverage,
 			VoteCount:      episode.VoteCount,
 		})
+		episode.Runtime = season.Episodes[i].Runtime
 		episodes = append(episodes, episode)
+	}
 
-		if err := t.cache.SetEpisode(ctx, id, seasonNumber, episodeNumber, episode); err != nil {
+	t.fillGenericEpisodeNames(ctx, idInt, seasonNumber, episodes)
+
+	for _, episode := range episodes {
+		if err := t.cache.SetEpisode(ctx, id, seasonNumber, episode.EpisodeNumber, episode); err != nil {
 			logger.FromContext(ctx).With("error", err).Error("failed to cache episode")
 		}
-
 	}
 
 	if err := t.cache.SetSeasonEpisodes(ctx, id, seasonNumber, episodes); err != nil {
-		logger.FromContext(ctx).With("error", err).Error("failed to cache episode")
+		logger.FromContext(ctx).With("error", err).Error("failed to cache season episodes")
 	}
 
-	if episodeNumber <= 0 || episodeNumber > len(episodes) {
-		return mediadata.Episode{}, fmt.Errorf("episode number %d out of range (season has %d episodes)", episodeNumber, len(episodes))
-	}
-
-	for _, episode := range episodes {
-		if episode.EpisodeNumber == episodeNumber {
-			return episode, nil
-		}
-	}
-
-	return mediadata.Episode{}, fmt.Errorf("episode not found")
+	return episodes, nil
 }
+
 func buildTvShow(tvShow *tmdb.TVDetails) mediadata.TvShow {
 	releaseYear := ""
 	if len(tvShow.FirstAirDate) >= 4 {
@@ -175,35 +221,79 @@ func buildTvShow(tvShow *tmdb.TVDetails) mediadata.TvShow {
 		PosterURL:   tmdbImageBaseUrl + tvShow.PosterPath,
 		Rating:      tvShow.VoteAverage,
 		RatingCount: tvShow.VoteCount,
+
+		OriginalTitle:    tvShow.OriginalName,
+		OriginalLanguage: tvShow.OriginalLanguage,
+		OriginCountry:    tvShow.OriginCountry,
 	}
 }
 
 func buildTvShowDetails(details *tmdb.TVDetails) mediadata.TvShowDetails {
-	releaseYear := ""
-	if len(details.FirstAirDate) >= 4 {
-		releaseYear = details.FirstAirDate[:4]
-	}
-	return mediadata.TvShowDetails{
-		TvShow: mediadata.TvShow{
-			ID:          strconv.FormatInt(details.ID, 10),
-			Title:       details.Name,
-			Overview:    details.Overview,
-			FistAirDate: details.FirstAirDate,
-			Year:        releaseYear,
-			PosterURL:   tmdbImageBaseUrl + details.PosterPath,
-			Rating:      details.VoteAverage,
-			RatingCount: details.VoteCount,
-		},
-		Status:       mediadata.Status(details.Status),
-		EpisodeCount: details.NumberOfEpisodes,
-		SeasonCount:  details.NumberOfSeasons,
-		Seasons:      buildSeasons(details.Seasons),
-		LastEpisode:  buildEpisode(details.LastEpisodeToAir),
-		NextEpisode:  buildEpisode(details.NextEpisodeToAir),
-		Cast:         buildTvShowCast(details.Credits.Cast),
-		Genres:       buildGenres(details.Genres),
-		Studio:       buildStudio(details.Networks),
+	show := mediadata.TvShowDetails{
+		TvShow:         buildTvShow(details),
+		EpisodeRuntime: firstRuntime(details.EpisodeRunTime),
+		Status:         mediadata.Status(details.Status),
+		EpisodeCount:   details.NumberOfEpisodes,
+		SeasonCount:    details.NumberOfSeasons,
+		Seasons:        buildSeasons(details.Seasons),
+		LastEpisode:    buildEpisode(details.LastEpisodeToAir),
+		NextEpisode:    buildEpisode(details.NextEpisodeToAir),
+		Cast:           buildTvShowCast(details.Credits.Cast),
+		Genres:         buildGenres(details.Genres),
+		Studio:         buildStudio(details.Networks),
+		Keywords:       tvShowKeywords(details),
+
+		ProductionCountries: buildCountries(details.ProductionCountries),
+		AlternativeTitles:   tvShowAlternativeTitles(details),
+	}
+	if details.TVExternalIDsAppend != nil && details.TVExternalIDs != nil {
+		show.ImdbID = details.TVExternalIDs.IMDbID
+		show.WikidataID = details.TVExternalIDs.WikiDataID
+		if details.TVExternalIDs.TVDBID != 0 {
+			show.TvdbID = strconv.FormatInt(details.TVExternalIDs.TVDBID, 10)
+		}
+	}
+	return show
+}
+
+func tvShowAlternativeTitles(details *tmdb.TVDetails) []string {
+	if details.TVAlternativeTitlesAppend == nil || details.AlternativeTitles == nil || details.AlternativeTitles.TVAlternativeTitlesResults == nil {
+		return nil
+	}
+	titles := make([]string, 0, len(details.AlternativeTitles.Results))
+	for _, title := range details.AlternativeTitles.Results {
+		titles = append(titles, title.Title)
+	}
+	return uniqueTitles(details.Name, details.OriginalName, titles)
+}
+
+func tvShowKeywords(details *tmdb.TVDetails) []string {
+	if details.TVKeywordsAppend == nil || details.Keywords.TVKeywords == nil || details.Keywords.TVKeywordsResults == nil {
+		return nil
+	}
+	keywords := make([]string, 0, len(details.Keywords.Results))
+	for _, keyword := range details.Keywords.Results {
+		keywords = append(keywords, keyword.Name)
+	}
+	return keywords
+}
+
+func tvShowCertification(details *tmdb.TVDetails, region string) string {
+	if details.TVContentRatingsAppend == nil || details.ContentRatings == nil || details.ContentRatings.TVContentRatingsResults == nil {
+		return ""
 	}
+	ratings := make(map[string]string)
+	for _, rating := range details.ContentRatings.Results {
+		ratings[rating.Iso3166_1] = rating.Rating
+	}
+	return pickCertification(ratings, region)
+}
+
+func firstRuntime(runtimes []int) int {
+	if len(runtimes) == 0 {
+		return 0
+	}
+	return runtimes[0]
 }
 
 func buildSeasons(seasons []struct {
@@ -265,6 +355,10 @@ func buildTvShowFromResult(result *tmdb.SearchTVShowsResults) []mediadata.TvShow
 			PosterPath:   tvShow.PosterPath,
 			VoteAverage:  tvShow.VoteAverage,
 			VoteCount:    tvShow.VoteCount,
+
+			OriginalName:     tvShow.OriginalName,
+			OriginalLanguage: tvShow.OriginalLanguage,
+			OriginCountry:    tvShow.OriginCountry,
 		})
 	}
 	return tvShows
@@ -0,0 +1,303 @@
+package mediarenamer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/nouuu/gonamer/internal/mediascanner/filescanner"
+	"github.com/nouuu/gonamer/internal/mediascanner/probe"
+	"github.com/nouuu/gonamer/pkg/config"
+	"github.com/nouuu/gonamer/pkg/logger"
+)
+
+// RenameAction tells what RenameFile did with a file.
+type RenameAction string
+
+const (
+	ActionRenamed   RenameAction = "renamed"
+	ActionUnchanged RenameAction = "unchanged"
+	// ActionSuffixed means the file was renamed next to an existing one,
+	// with a " (1)" suffix.
+	ActionSuffixed RenameAction = "suffixed"
+	ActionSkipped  RenameAction = "skipped"
+	// ActionOverwritten means the existing destination file was replaced.
+	ActionOverwritten RenameAction = "overwritten"
+	// ActionQuarantined means the file lost the conflict and was moved to the
+	// quarantine folder.
+	ActionQuarantined RenameAction = "quarantined"
+	// ActionReplaced means the file won the conflict and the existing one was
+	// moved to the quarantine folder.
+	ActionReplaced RenameAction = "replaced"
+	// ActionPending is only reported in dry run, when the policy would ask.
+	ActionPending RenameAction = "pending"
+)
+
+// RenameResult reports what happened to a renamed file.
+type RenameResult struct {
+	Source string
+	// Destination is where the file ends up, or would in dry run.
+	Destination string
+	Action      RenameAction
+	// Existing is the file that was already at the destination, if any.
+	Existing string
+	// Quarantined is where the losing file of a conflict was moved.
+	Quarantined string
+	// Reason explains how a conflict was resolved.
+	Reason string
+	// Route is the name of the routing rule that chose the destination.
+	Route string
+}
+
+// ConflictFile describes one side of a conflict.
+type ConflictFile struct {
+	Path       string
+	Size       int64
+	Resolution string
+	Source     string
+}
+
+// Quality returns the resolution and source of the file, e.g. "1080p WEB-DL".
+func (f ConflictFile) Quality() string {
+	return strings.TrimSpace(f.Resolution + " " + f.Source)
+}
+
+func (f ConflictFile) String() string {
+	if f.Quality() == "" {
+		return "unknown quality, " + formatSize(f.Size)
+	}
+	return f.Quality() + ", " + formatSize(f.Size)
+}
+
+// Conflict is a rename whose destination already exists.
+type Conflict struct {
+	Source   ConflictFile
+	Existing ConflictFile
+}
+
+// ConflictAsker lets the user choose the policy applied to a conflict. It
+// must not return config.ConflictAsk.
+type ConflictAsker func(ctx context.Context, conflict Conflict) (config.ConflictPolicy, error)
+
+// SetConflictAsker sets the prompt used by the "ask" policy. Without one,
+// "ask" falls back to "suffix".
+func (mr *MediaRenamer) SetConflictAsker(ask ConflictAsker) {
+	mr.askConflict = ask
+}
+
+// resolveConflict decides what to do with result.Source now that
+// result.Existing already exists, and fills the action, reason and paths.
+func (mr *MediaRenamer) resolveConflict(ctx context.Context, result RenameResult, dryrun bool) (RenameResult, error) {
+	policy := mr.onConflict
+	if policy == config.ConflictAsk {
+		switch {
+		case dryrun:
+			result.Action = ActionPending
+			result.Reason = "destination exists, will ask"
+			return result, nil
+		case mr.askConflict == nil:
+			policy = config.ConflictSuffix
+		default:
+			conflict := Conflict{Source: describeFile(result.Source), Existing: describeFile(result.Existing)}
+			chosen, err := mr.askConflict(ctx, conflict)
+			if err != nil {
+				return result, err
+			}
+			policy = chosen
+		}
+	}
+
+	switch policy {
+	case config.ConflictSkip:
+		result.Action = ActionSkipped
+		result.Reason = "destination exists"
+	case config.ConflictOverwrite:
+		result.Action = ActionOverwritten
+		result.Reason = "destination exists"
+	case config.ConflictKeepLarger, config.ConflictKeepHigherQuality, config.ConflictQuarantine:
+		source, existing := describeFile(result.Source), describeFile(result.Existing)
+		var sourceWins bool
+		if policy == config.ConflictKeepLarger {
+			sourceWins = source.Size > existing.Size
+			result.Reason = fmt.Sprintf("%s vs existing %s", formatSize(source.Size), formatSize(existing.Size))
+		} else {
+			sourceWins = compareFiles(source, existing) > 0
+			result.Reason = fmt.Sprintf("%s vs existing %s", source, existing)
+		}
+
+		switch {
+		case policy == config.ConflictQuarantine && sourceWins:
+			result.Action = ActionReplaced
+			result.Quarantined = mr.quarantinePath(result.Existing)
+		case policy == config.ConflictQuarantine:
+			result.Action = ActionQuarantined
+			result.Quarantined = mr.quarantinePath(result.Source)
+			result.Destination = result.Quarantined
+		case sourceWins:
+			result.Action = ActionOverwritten
+		default:
+			result.Action = ActionSkipped
+		}
+	default:
+		result.Destination = findUniqueFilename(result.Destination)
+		result.Action = ActionSuffixed
+		result.Reason = "destination exists"
+	}
+
+	logger.FromContext(ctx).Infof("Conflict on %s resolved with '%s': %s (%s)", result.Existing, policy, result.Action, result.Reason)
+	return result, nil
+}
+
+// quarantinePath returns a free path for file in the quarantine folder.
+func (mr *MediaRenamer) quarantinePath(file string) string {
+	return findUniqueFilename(filepath.Join(mr.quarantineDir, filepath.Base(file)))
+}
+
+// applyConflict moves the files of a resolved conflict, except the source to
+// its destination which is left to the caller.
+func applyConflict(result RenameResult) error {
+	switch result.Action {
+	case ActionOverwritten:
+		return os.Remove(result.Existing)
+	case ActionReplaced:
+		return moveFile(result.Existing, result.Quarantined)
+	}
+	return nil
+}
+
+// moveFile moves source to destination, creating its folder. A destination on
+// another filesystem, such as a quarantine folder or a route root on another
+// mount, can't be renamed to, so the file is copied there instead.
+func moveFile(source, destination string) error {
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return err
+	}
+	err := os.Rename(source, destination)
+	if errors.Is(err, syscall.EXDEV) {
+		return copyAndRemove(source, destination)
+	}
+	return err
+}
+
+// copyAndRemove copies source to destination, syncs it to the disk and only
+// then removes source. A partial copy is removed on failure.
+func copyAndRemove(source, destination string) (err error) {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(destination, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(destination)
+		}
+	}()
+	if _, err = io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err = out.Sync(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	// Media servers sort by date added, which a copy would reset.
+	if err = os.Chtimes(destination, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	return os.Remove(source)
+}
+
+// describeFile reads the size and quality of path. The quality comes from the
+// file name, with the resolution read from the container when possible, since
+// renamed files usually lost their release tokens. Their source can't be
+// probed and stays unknown.
+func describeFile(path string) ConflictFile {
+	file := ConflictFile{Path: path}
+	if info, err := os.Stat(path); err == nil {
+		file.Size = info.Size()
+	}
+
+	name := filepath.Base(path)
+	quality := filescanner.ParseQuality(strings.TrimSuffix(name, filepath.Ext(name)))
+	if media, err := probe.File(path); err == nil && media.Resolution() != "" {
+		quality.Resolution = media.Resolution()
+	}
+	file.Resolution, file.Source = quality.Resolution, quality.Source
+	return file
+}
+
+var (
+	resolutionRanks = []string{"480p", "576p", "720p", "1080p", "2160p"}
+	sourceRanks     = []string{"CAM", "DVDRip", "HDRip", "HDTV", "WEBRip", "WEB-DL", "BluRay", "Remux"}
+)
+
+// compareFiles orders files by resolution, then source, then size. A
+// resolution or source only known for one of the files, as for a renamed file
+// that lost its release tokens, doesn't decide.
+func compareFiles(a, b ConflictFile) int {
+	if c := compareRanks(resolutionRanks, a.Resolution, b.Resolution); c != 0 {
+		return c
+	}
+	if c := compareRanks(sourceRanks, a.Source, b.Source); c != 0 {
+		return c
+	}
+	switch {
+	case a.Size > b.Size:
+		return 1
+	case a.Size < b.Size:
+		return -1
+	}
+	return 0
+}
+
+// compareRanks compares the positions of a and b in ranks, or returns 0 when
+// either is unknown.
+func compareRanks(ranks []string, a, b string) int {
+	rankA, rankB := rank(ranks, a), rank(ranks, b)
+	if rankA == 0 || rankB == 0 {
+		return 0
+	}
+	return rankA - rankB
+}
+
+// rank returns the position of value in ranks starting at 1, or 0 when
+// unknown.
+func rank(ranks []string, value string) int {
+	for i, r := range ranks {
+		if r == value {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// formatSize returns a human readable size, e.g. "1.4 GiB".
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,149 @@
+package mediarenamer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nouuu/gonamer/pkg/config"
+)
+
+func writeFile(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
+
+func TestRenameFileConflicts(t *testing.T) {
+	tests := []struct {
+		policy      config.ConflictPolicy
+		source      string
+		sourceSize  int
+		wantAction  RenameAction
+		wantDest    string
+		wantSize    int64 // size of the file left at "Movie.mkv"
+		wantSource  bool  // whether the source is still in place
+		quarantined bool
+	}{
+		{config.ConflictSuffix, "a.mkv", 20, ActionSuffixed, "Movie (1).mkv", 10, false, false},
+		{config.ConflictSkip, "a.mkv", 20, ActionSkipped, "Movie.mkv", 10, true, false},
+		{config.ConflictOverwrite, "a.mkv", 5, ActionOverwritten, "Movie.mkv", 5, false, false},
+		{config.ConflictKeepLarger, "a.mkv", 20, ActionOverwritten, "Movie.mkv", 20, false, false},
+		{config.ConflictKeepLarger, "a.mkv", 5, ActionSkipped, "Movie.mkv", 10, true, false},
+		// The quality of the existing file is unknown, so the size decides.
+		{config.ConflictKeepHigherQuality, "a.2160p.WEB-DL.mkv", 5, ActionSkipped, "Movie.mkv", 10, true, false},
+		{config.ConflictQuarantine, "a.2160p.mkv", 20, ActionReplaced, "Movie.mkv", 20, false, true},
+		{config.ConflictQuarantine, "a.mkv", 5, ActionQuarantined, "q/a.mkv", 10, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy)+" "+tt.source, func(t *testing.T) {
+			dir := t.TempDir()
+			source, destination := filepath.Join(dir, tt.source), filepath.Join(dir, "Movie.mkv")
+			writeFile(t, source, tt.sourceSize)
+			writeFile(t, destination, 10)
+
+			mr := NewMediaRenamer(nil, nil, config.RenamerConfig{OnConflict: tt.policy, QuarantineDir: filepath.Join(dir, "q")})
+			result, err := mr.RenameFile(context.Background(), source, destination, false)
+			if err != nil {
+				t.Fatalf("RenameFile() error = %v", err)
+			}
+			if result.Action != tt.wantAction {
+				t.Errorf("Action = %s, want %s", result.Action, tt.wantAction)
+			}
+			if want := filepath.Join(dir, tt.wantDest); result.Destination != want {
+				t.Errorf("Destination = %s, want %s", result.Destination, want)
+			}
+			if got := fileSize(t, destination); got != tt.wantSize {
+				t.Errorf("destination size = %d, want %d", got, tt.wantSize)
+			}
+			if _, err := os.Stat(source); (err == nil) != tt.wantSource {
+				t.Errorf("source still exists = %v, want %v", err == nil, tt.wantSource)
+			}
+			if (result.Quarantined != "") != tt.quarantined {
+				t.Errorf("Quarantined = %q", result.Quarantined)
+			}
+			if result.Quarantined != "" {
+				fileSize(t, result.Quarantined)
+			}
+		})
+	}
+}
+
+func TestRenameFileUnchangedAndDryRun(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Movie.mkv")
+	writeFile(t, path, 10)
+
+	mr := NewMediaRenamer(nil, nil, config.RenamerConfig{OnConflict: config.ConflictAsk})
+	result, err := mr.RenameFile(context.Background(), path, path, false)
+	if err != nil || result.Action != ActionUnchanged {
+		t.Errorf("RenameFile() to itself = %s, %v, want unchanged", result.Action, err)
+	}
+
+	other := filepath.Join(dir, "other.mkv")
+	writeFile(t, other, 10)
+	result, err = mr.RenameFile(context.Background(), other, path, true)
+	if err != nil || result.Action != ActionPending {
+		t.Errorf("RenameFile() in dry run = %s, %v, want pending", result.Action, err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("dry run moved the source: %v", err)
+	}
+}
+
+func TestCopyAndRemove(t *testing.T) {
+	dir := t.TempDir()
+	source, destination := filepath.Join(dir, "Movie.mkv"), filepath.Join(dir, "Movie (1999).mkv")
+	writeFile(t, source, 42)
+
+	if err := copyAndRemove(source, destination); err != nil {
+		t.Fatalf("copyAndRemove() error = %v", err)
+	}
+	if _, err := os.Stat(source); !os.IsNotExist(err) {
+		t.Errorf("copyAndRemove() left the source, stat error = %v", err)
+	}
+	if got := fileSize(t, destination); got != 42 {
+		t.Errorf("copied size = %d, want 42", got)
+	}
+
+	writeFile(t, source, 10)
+	if err := copyAndRemove(source, destination); err == nil {
+		t.Error("copyAndRemove() replaced an existing destination")
+	}
+	if got := fileSize(t, destination); got != 42 {
+		t.Errorf("existing destination size = %d, want it untouched", got)
+	}
+	if got := fileSize(t, source); got != 10 {
+		t.Errorf("source size = %d, want it kept after a failed copy", got)
+	}
+}
+
+func TestCompareFiles(t *testing.T) {
+	tests := []struct {
+		a, b ConflictFile
+		want int // sign of the comparison
+	}{
+		{ConflictFile{Resolution: "2160p", Size: 5}, ConflictFile{Resolution: "1080p", Source: "BluRay", Size: 20}, 1},
+		{ConflictFile{Resolution: "1080p", Source: "BluRay", Size: 5}, ConflictFile{Resolution: "1080p", Source: "WEB-DL", Size: 20}, 1},
+		{ConflictFile{Resolution: "1080p", Source: "WEB-DL", Size: 10}, ConflictFile{Resolution: "1080p", Size: 20}, -1},
+		{ConflictFile{Source: "WEB-DL", Size: 5}, ConflictFile{Resolution: "720p", Size: 10}, -1},
+		{ConflictFile{Size: 10}, ConflictFile{Size: 10}, 0},
+	}
+	for _, tt := range tests {
+		got := compareFiles(tt.a, tt.b)
+		if (got > 0) != (tt.want > 0) || (got < 0) != (tt.want < 0) {
+			t.Errorf("compareFiles(%v, %v) = %d, want sign of %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
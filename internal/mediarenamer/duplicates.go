@@ -0,0 +1,193 @@
+package mediarenamer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/internal/mediascanner"
+	"github.com/nouuu/gonamer/pkg/config"
+)
+
+// DuplicateGroup is a set of files matched to the same movie or episode.
+type DuplicateGroup struct {
+	// Key identifies the work, e.g. "movie:603" or "tv:1396:s01e01".
+	Key   string
+	Title string
+	Files []ConflictFile
+}
+
+// DuplicateTracker records the files matched to each movie or episode during
+// a run, in the order they were first seen. It also holds their renames until
+// the duplicates are resolved, so that the file kept gets the name without a
+// suffix.
+type DuplicateTracker struct {
+	mu      sync.Mutex
+	groups  map[string]*DuplicateGroup
+	keys    []string
+	renames map[string]func(context.Context) error
+	pending []string
+}
+
+func NewDuplicateTracker() *DuplicateTracker {
+	return &DuplicateTracker{
+		groups:  make(map[string]*DuplicateGroup),
+		renames: make(map[string]func(context.Context) error),
+	}
+}
+
+// MovieKey identifies movie. Other editions, the parts of a split movie and
+// extras are not duplicates of each other, so they get their own key.
+func MovieKey(movie mediadata.Movie, fileMovie mediascanner.Movie) string {
+	key := "movie:" + movie.ID
+	if fileMovie.Extra != "" {
+		// Several trailers of a movie are expected.
+		key += ":extra:" + fileMovie.OriginalFilename
+	}
+	if fileMovie.Edition != "" {
+		key += ":" + strings.ToLower(fileMovie.Edition)
+	}
+	if fileMovie.Part != 0 {
+		key += fmt.Sprintf(":pt%d", fileMovie.Part)
+	}
+	return key
+}
+
+func EpisodeKey(tvShow mediadata.TvShow, episode mediadata.Episode) string {
+	return fmt.Sprintf("tv:%s:s%02de%02d", tvShow.ID, episode.SeasonNumber, episode.EpisodeNumber)
+}
+
+// AddMovie records path as the file fileMovie matched to movie.
+func (t *DuplicateTracker) AddMovie(movie mediadata.Movie, fileMovie mediascanner.Movie, path string) {
+	title := fmt.Sprintf("%s (%s)", movie.Title, movie.Year)
+	if fileMovie.Edition != "" {
+		title += " " + fileMovie.Edition
+	}
+	if fileMovie.Part != 0 {
+		title += fmt.Sprintf(" - pt%d", fileMovie.Part)
+	}
+	t.Add(MovieKey(movie, fileMovie), title, path)
+}
+
+// AddEpisode records path as a file matched to episode.
+func (t *DuplicateTracker) AddEpisode(tvShow mediadata.TvShow, episode mediadata.Episode, path string) {
+	title := fmt.Sprintf("%s - %dx%02d - %s", tvShow.Title, episode.SeasonNumber, episode.EpisodeNumber, episode.Name)
+	t.Add(EpisodeKey(tvShow, episode), title, path)
+}
+
+// Add records path under key, once.
+func (t *DuplicateTracker) Add(key, title, path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	group, ok := t.groups[key]
+	if !ok {
+		group = &DuplicateGroup{Key: key, Title: title}
+		t.groups[key] = group
+		t.keys = append(t.keys, key)
+	}
+	if slices.ContainsFunc(group.Files, func(f ConflictFile) bool { return f.Path == path }) {
+		return
+	}
+	group.Files = append(group.Files, ConflictFile{Path: path})
+}
+
+// Defer holds the rename of the file at path until Renames is called.
+func (t *DuplicateTracker) Defer(path string, rename func(context.Context) error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.renames[path]; !ok {
+		t.pending = append(t.pending, path)
+	}
+	t.renames[path] = rename
+}
+
+// Discard drops the rename held for the file at path, which leaves the
+// library or stays where it is.
+func (t *DuplicateTracker) Discard(path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.renames, path)
+}
+
+// Renames returns the renames still held, in the order the files were
+// matched, and forgets them.
+func (t *DuplicateTracker) Renames() []func(context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var renames []func(context.Context) error
+	for _, path := range t.pending {
+		if rename, ok := t.renames[path]; ok {
+			renames = append(renames, rename)
+		}
+	}
+	t.renames = make(map[string]func(context.Context) error)
+	t.pending = nil
+	return renames
+}
+
+// Duplicates returns the groups with more than one file, with the size and
+// quality of each file.
+func (t *DuplicateTracker) Duplicates() []DuplicateGroup {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var duplicates []DuplicateGroup
+	for _, key := range t.keys {
+		group := t.groups[key]
+		if len(group.Files) < 2 {
+			continue
+		}
+		described := DuplicateGroup{Key: group.Key, Title: group.Title}
+		for _, f := range group.Files {
+			described.Files = append(described.Files, describeFile(f.Path))
+		}
+		duplicates = append(duplicates, described)
+	}
+	return duplicates
+}
+
+// Best returns the index of the file the policy keeps, or -1 when the policy
+// doesn't choose by itself.
+func (g DuplicateGroup) Best(policy config.DuplicatePolicy) int {
+	var better func(a, b ConflictFile) bool
+	switch policy {
+	case config.DuplicatesKeepHigherQuality:
+		better = func(a, b ConflictFile) bool { return compareFiles(a, b) > 0 }
+	case config.DuplicatesKeepLarger:
+		better = func(a, b ConflictFile) bool { return a.Size > b.Size }
+	default:
+		return -1
+	}
+
+	best := 0
+	for i := 1; i < len(g.Files); i++ {
+		if better(g.Files[i], g.Files[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// Quarantine moves path to the quarantine folder and returns its new path.
+// Nothing is moved in dry run.
+func (mr *MediaRenamer) Quarantine(ctx context.Context, path string, dryrun bool) (string, error) {
+	destination := mr.quarantinePath(path)
+	if dryrun {
+		return destination, nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", err
+	}
+	if err := moveFile(path, destination); err != nil {
+		return "", fmt.Errorf("failed to move %s to quarantine: %w", filepath.Base(path), err)
+	}
+	return destination, nil
+}
@@ -0,0 +1,72 @@
+package mediarenamer
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/internal/mediascanner"
+	"github.com/nouuu/gonamer/pkg/config"
+)
+
+func TestDuplicateTracker(t *testing.T) {
+	dir := t.TempDir()
+	hd, uhd, other := filepath.Join(dir, "Matrix.1080p.mkv"), filepath.Join(dir, "Matrix.2160p.mkv"), filepath.Join(dir, "Heat.mkv")
+	writeFile(t, hd, 20)
+	writeFile(t, uhd, 10)
+	writeFile(t, other, 10)
+
+	matrix := mediadata.Movie{ID: "603", Title: "The Matrix", Year: "1999"}
+	tracker := NewDuplicateTracker()
+	tracker.AddMovie(matrix, mediascanner.Movie{}, hd)
+	tracker.AddMovie(mediadata.Movie{ID: "949", Title: "Heat", Year: "1995"}, mediascanner.Movie{}, other)
+	tracker.AddMovie(matrix, mediascanner.Movie{Part: 2}, other)
+	tracker.AddMovie(matrix, mediascanner.Movie{}, uhd)
+	tracker.AddMovie(matrix, mediascanner.Movie{}, uhd)
+
+	groups := tracker.Duplicates()
+	if len(groups) != 1 {
+		t.Fatalf("Duplicates() = %d groups, want 1", len(groups))
+	}
+	group := groups[0]
+	if group.Key != "movie:603" || len(group.Files) != 2 {
+		t.Fatalf("Duplicates() = %+v, want movie:603 with 2 files", group)
+	}
+
+	for policy, want := range map[config.DuplicatePolicy]int{
+		config.DuplicatesKeepHigherQuality: 1,
+		config.DuplicatesKeepLarger:        0,
+		config.DuplicatesAsk:               -1,
+	} {
+		if got := group.Best(policy); got != want {
+			t.Errorf("Best(%s) = %d, want %d", policy, got, want)
+		}
+	}
+}
+
+func TestDuplicateTrackerRenames(t *testing.T) {
+	var renamed []string
+	rename := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			renamed = append(renamed, name)
+			return nil
+		}
+	}
+
+	tracker := NewDuplicateTracker()
+	tracker.Defer("a.mkv", rename("a"))
+	tracker.Defer("b.mkv", rename("b"))
+	tracker.Defer("c.mkv", rename("c"))
+	tracker.Discard("b.mkv")
+	for _, rename := range tracker.Renames() {
+		_ = rename(context.Background())
+	}
+	if want := []string{"a", "c"}; !slices.Equal(renamed, want) {
+		t.Errorf("Renames() ran %v, want %v", renamed, want)
+	}
+	if got := tracker.Renames(); len(got) != 0 {
+		t.Errorf("Renames() = %d renames after a first call, want none", len(got))
+	}
+}
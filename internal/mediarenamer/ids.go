@@ -0,0 +1,95 @@
+package mediarenamer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/internal/mediascanner"
+	"github.com/nouuu/gonamer/pkg/logger"
+)
+
+var errNoIDs = errors.New("no IDs")
+
+// movieByIDs resolves the IDs found by the scanner, trying the TMDB ID first,
+// then the IMDb and TVDB IDs through TMDB's find endpoint.
+func (mr *MediaRenamer) movieByIDs(ctx context.Context, ids mediascanner.IDs) (mediadata.Movie, error) {
+	err := errNoIDs
+	if ids.TMDB != "" {
+		var movie mediadata.Movie
+		if movie, err = mr.movieClient.GetMovie(ctx, ids.TMDB); err == nil {
+			return movie, nil
+		}
+	}
+	for _, external := range externalIDs(ids) {
+		var movie mediadata.Movie
+		if movie, err = mr.movieClient.FindMovie(ctx, external.source, external.id); err == nil {
+			return movie, nil
+		}
+	}
+	return mediadata.Movie{}, err
+}
+
+// tvShowByIDs is movieByIDs for the show of an episode.
+func (mr *MediaRenamer) tvShowByIDs(ctx context.Context, ids mediascanner.IDs) (mediadata.TvShow, error) {
+	err := errNoIDs
+	if ids.TMDB != "" {
+		var tvShow mediadata.TvShow
+		if tvShow, err = mr.tvShowClient.GetTvShow(ctx, ids.TMDB); err == nil {
+			return tvShow, nil
+		}
+	}
+	for _, external := range externalIDs(ids) {
+		var tvShow mediadata.TvShow
+		if tvShow, err = mr.tvShowClient.FindTvShow(ctx, external.source, external.id); err == nil {
+			return tvShow, nil
+		}
+	}
+	return mediadata.TvShow{}, err
+}
+
+type externalID struct {
+	source mediadata.ExternalSource
+	id     string
+}
+
+func externalIDs(ids mediascanner.IDs) []externalID {
+	var external []externalID
+	if ids.IMDb != "" {
+		external = append(external, externalID{mediadata.SourceIMDb, ids.IMDb})
+	}
+	if ids.TVDB != "" {
+		external = append(external, externalID{mediadata.SourceTVDB, ids.TVDB})
+	}
+	return external
+}
+
+// suggestMovieByIDs returns the movie identified by the IDs of the file as the
+// only suggestion, so that tagged files never depend on a fuzzy search.
+func (mr *MediaRenamer) suggestMovieByIDs(ctx context.Context, movie mediascanner.Movie) (MovieSuggestions, bool) {
+	log := logger.FromContext(ctx)
+	found, err := mr.movieByIDs(ctx, movie.IDs)
+	if err != nil {
+		log.With("error", err, "ids", movie.IDs).Warnf("Could not identify '%s' by its IDs, searching by name", movie.OriginalFilename)
+		return MovieSuggestions{}, false
+	}
+	log.Infof("Identified '%s' as '%s' (%s) by its IDs", movie.OriginalFilename, found.Title, found.Year)
+	suggestions := MovieSuggestions{Movie: movie, SuggestedMovies: []mediadata.Movie{found}}
+	suggestions.SuggestedMovies, suggestions.RuntimeMismatches = mr.rankMoviesByRuntime(ctx, movie, suggestions.SuggestedMovies)
+	return suggestions, true
+}
+
+// suggestEpisodeByIDs is suggestMovieByIDs for the show of an episode.
+func (mr *MediaRenamer) suggestEpisodeByIDs(ctx context.Context, episode mediascanner.Episode) (EpisodeSuggestions, bool) {
+	log := logger.FromContext(ctx)
+	tvShow, err := mr.tvShowByIDs(ctx, episode.IDs)
+	if err == nil {
+		var found []SuggestedEpisode
+		if found, err = mr.findEpisodes(ctx, episode, []mediadata.TvShow{tvShow}); err == nil {
+			log.Infof("Identified '%s' as '%s' by its IDs", episode.OriginalFilename, tvShow.Title)
+			return EpisodeSuggestions{Episode: episode, SuggestedEpisodes: mr.rankEpisodesByRuntime(ctx, episode, found)}, true
+		}
+	}
+	log.With("error", err, "ids", episode.IDs).Warnf("Could not identify '%s' by its IDs, searching by name", episode.OriginalFilename)
+	return EpisodeSuggestions{}, false
+}
@@ -0,0 +1,36 @@
+package mediarenamer
+
+import (
+	"context"
+
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/pkg/logger"
+)
+
+// localizeMovie fetches a movie found in another search language in the
+// naming language. When it can't be fetched, e.g. offline without cache, the
+// movie keeps its TitleLanguage so that the other title is flagged.
+func (mr *MediaRenamer) localizeMovie(ctx context.Context, movie mediadata.Movie) mediadata.Movie {
+	if movie.TitleLanguage == "" {
+		return movie
+	}
+	localized, err := mr.movieClient.GetMovie(ctx, movie.ID)
+	if err != nil {
+		logger.FromContext(ctx).With("error", err).Warnf("Could not get '%s' in the naming language, keeping its %s title", movie.Title, movie.TitleLanguage)
+		return movie
+	}
+	return localized
+}
+
+// localizeTvShow is localizeMovie for tv shows.
+func (mr *MediaRenamer) localizeTvShow(ctx context.Context, tvShow mediadata.TvShow) mediadata.TvShow {
+	if tvShow.TitleLanguage == "" {
+		return tvShow
+	}
+	localized, err := mr.tvShowClient.GetTvShow(ctx, tvShow.ID)
+	if err != nil {
+		logger.FromContext(ctx).With("error", err).Warnf("Could not get '%s' in the naming language, keeping its %s title", tvShow.Title, tvShow.TitleLanguage)
+		return tvShow
+	}
+	return localized
+}
@@ -16,6 +16,9 @@ import (
 	"github.com/nouuu/gonamer/internal/mediascanner"
 	"github.com/nouuu/gonamer/pkg/config"
 	"github.com/nouuu/gonamer/pkg/logger"
+	"github.com/nouuu/gonamer/pkg/pattern"
+	"github.com/nouuu/gonamer/pkg/sanitize"
+	"github.com/nouuu/gonamer/pkg/sortname"
 	"go.uber.org/zap"
 )
 
@@ -38,16 +41,42 @@ func findUniqueFilename(destination string) string {
 type MediaRenamer struct {
 	movieClient  mediadata.MovieClient
 	tvShowClient mediadata.TvShowClient
+	sanitizer    *sanitize.Sanitizer
+	sorter       *sortname.Sorter
+	// root is the folder relative patterns are resolved against, the folder
+	// of each file when empty.
+	root   string
+	routes []config.RouteRule
+	// relaxation lists the fallback searches of movies without results.
+	relaxation []config.RelaxStep
+	// searchDepth is the number of result pages ranked when matching files.
+	searchDepth int
+
+	onConflict    config.ConflictPolicy
+	quarantineDir string
+	askConflict   ConflictAsker
 }
 
 type MovieSuggestions struct {
 	Movie           mediascanner.Movie
 	SuggestedMovies []mediadata.Movie
+	// RuntimeMismatches holds the suggested movies, by ID, whose runtime is
+	// far from the file duration.
+	RuntimeMismatches map[string]RuntimeMismatch
+	// Strategy is the relaxation step the suggestions were found with, empty
+	// when the search by name and year found them.
+	Strategy config.RelaxStep
+	// Search is the search the suggestions come from, empty when the file
+	// was identified by its IDs.
+	Search MovieSearch
 }
 
 type SuggestedEpisode struct {
 	TvShow  mediadata.TvShow
 	Episode mediadata.Episode
+	// RuntimeMismatch is set when the episode runtime is far from the file
+	// duration.
+	RuntimeMismatch *RuntimeMismatch
 }
 
 type EpisodeSuggestions struct {
@@ -58,8 +87,20 @@ type EpisodeSuggestions struct {
 type FindMovieSuggestionCallback func(suggestion MovieSuggestions, err error)
 type FindEpisodeSuggestionCallback func(suggestion EpisodeSuggestions, err error)
 
-func NewMediaRenamer(movieClient mediadata.MovieClient, tvShowClient mediadata.TvShowClient) *MediaRenamer {
-	return &MediaRenamer{movieClient: movieClient, tvShowClient: tvShowClient}
+func NewMediaRenamer(movieClient mediadata.MovieClient, tvShowClient mediadata.TvShowClient, renamerCfg config.RenamerConfig) *MediaRenamer {
+	return &MediaRenamer{
+		movieClient:  movieClient,
+		tvShowClient: tvShowClient,
+		sanitizer:    sanitize.New(renamerCfg.Sanitize.Mode, renamerCfg.Sanitize.MaxLength),
+		sorter:       sortname.New(renamerCfg.Sort.Language, renamerCfg.Sort.Articles),
+		root:         renamerCfg.Patterns.Root,
+		routes:       renamerCfg.Routes,
+		relaxation:   renamerCfg.Relaxation,
+		searchDepth:  renamerCfg.SearchDepth,
+
+		onConflict:    renamerCfg.OnConflict,
+		quarantineDir: renamerCfg.QuarantineDir,
+	}
 }
 
 // DÜZELTME: Bu fonksiyon zincirinin imzaları, config parametresini taşıyacak şekilde düzeltildi.
@@ -81,72 +122,183 @@ func (mr *MediaRenamer) FindEpisodeSuggestions(ctx context.Context, episodes []m
 	log.Infof("Finished getting suggestions for %d episodes in %s", len(episodes), time.Since(start))
 	return suggestions
 }
-func (mr *MediaRenamer) RenameMovie(ctx context.Context, fileMovie mediascanner.Movie, mediadataMovie mediadata.Movie, pattern string, dryrun bool) (string, error) {
-	filename := GenerateMovieFilename(pattern, mediadataMovie, fileMovie)
-	var destination string
-	if filepath.IsAbs(filename) {
-		destination = filename
-	} else {
-		destination = filepath.Join(filepath.Dir(fileMovie.FullPath), filename)
-	}
-	return mr.RenameFile(ctx, fileMovie.FullPath, destination, dryrun)
+func (mr *MediaRenamer) RenameMovie(ctx context.Context, fileMovie mediascanner.Movie, mediadataMovie mediadata.Movie, moviePattern string, dryrun bool) (RenameResult, error) {
+	tmpl, err := ParseMoviePattern(moviePattern)
+	if err != nil {
+		return RenameResult{}, err
+	}
+	details := mediadata.MovieDetails{Movie: mr.localizeMovie(ctx, mediadataMovie)}
+	if tmpl.ReferencesAny(pattern.DetailFields) || mr.hasRoutes(config.Movie) {
+		if details, err = mr.movieClient.GetMovieDetails(ctx, mediadataMovie.ID); err != nil {
+			return RenameResult{}, fmt.Errorf("failed to get details of movie %s: %w", mediadataMovie.ID, err)
+		}
+	}
+	rule, routed := mr.route(movieRouteItem(details))
+	if routed && rule.Pattern != "" {
+		if tmpl, err = ParseMoviePattern(rule.Pattern); err != nil {
+			return RenameResult{}, err
+		}
+	}
+	filename := GenerateMovieFilename(tmpl, details, fileMovie, mr.sanitizer, mr.sorter)
+	destination := mr.destination(fileMovie.FullPath, filename, tmpl, rule)
+	result, err := mr.RenameFile(ctx, fileMovie.FullPath, destination, dryrun)
+	if routed && (rule.Pattern != "" || destination != mr.destination(fileMovie.FullPath, filename, tmpl, config.RouteRule{})) {
+		result.Route = rule.Name
+	}
+	return result, err
+}
+
+func (mr *MediaRenamer) RenameEpisode(ctx context.Context, fileEpisode mediascanner.Episode, tvShow mediadata.TvShow, episode mediadata.Episode, showPattern string, dryrun bool) (RenameResult, error) {
+	tmpl, err := ParseEpisodePattern(showPattern)
+	if err != nil {
+		return RenameResult{}, err
+	}
+	details := mediadata.TvShowDetails{TvShow: mr.localizeTvShow(ctx, tvShow)}
+	if tmpl.ReferencesAny(pattern.DetailFields) || mr.hasRoutes(config.TvShow) {
+		if details, err = mr.tvShowClient.GetTvShowDetails(ctx, tvShow.ID); err != nil {
+			return RenameResult{}, fmt.Errorf("failed to get details of tv show %s: %w", tvShow.ID, err)
+		}
+	}
+	rule, routed := mr.route(episodeRouteItem(details, episode))
+	if routed && rule.Pattern != "" {
+		if tmpl, err = ParseEpisodePattern(rule.Pattern); err != nil {
+			return RenameResult{}, err
+		}
+	}
+	// The season was fetched to find the episode, so it is usually cached.
+	var season []mediadata.Episode
+	if tmpl.ReferencesAny([]string{pattern.FieldEpisode, pattern.FieldEpisodeEnd}) {
+		if season, err = mr.tvShowClient.GetSeasonEpisodes(ctx, tvShow.ID, episode.SeasonNumber); err != nil {
+			logger.FromContext(ctx).With("error", err).Warnf("Could not get season %d of tv show %s, episode numbers keep their default width", episode.SeasonNumber, tvShow.ID)
+		}
+	}
+	filename := GenerateEpisodeFilename(tmpl, details, episode, season, fileEpisode, mr.sanitizer, mr.sorter)
+	destination := mr.destination(fileEpisode.FullPath, filename, tmpl, rule)
+	result, err := mr.RenameFile(ctx, fileEpisode.FullPath, destination, dryrun)
+	if routed && (rule.Pattern != "" || destination != mr.destination(fileEpisode.FullPath, filename, tmpl, config.RouteRule{})) {
+		result.Route = rule.Name
+	}
+	return result, err
+}
+
+// destination returns where the file at source goes once renamed to filename.
+// Relative names are resolved against the root of the routing rule, if any.
+func (mr *MediaRenamer) destination(source, filename string, tmpl *pattern.Template, rule config.RouteRule) string {
+	switch {
+	case filepath.IsAbs(filename) && rule.Root != "":
+		// The root of the rule replaces the folder an absolute pattern starts
+		// with, e.g. "/media/Movies" in "/media/Movies/{name}{extension}".
+		if rel, err := filepath.Rel(patternDir(tmpl.String()), filename); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.Join(rule.Root, rel)
+		}
+		return filename
+	case filepath.IsAbs(filename):
+		return filename
+	case rule.Root != "":
+		return filepath.Join(rule.Root, filename)
+	case mr.root != "":
+		return filepath.Join(mr.root, filename)
+	default:
+		return filepath.Join(filepath.Dir(source), filename)
+	}
 }
 
-func (mr *MediaRenamer) RenameEpisode(ctx context.Context, fileEpisode mediascanner.Episode, tvShow mediadata.TvShow, episode mediadata.Episode, pattern string, dryrun bool) (string, error) {
-	filename := GenerateEpisodeFilename(pattern, tvShow, episode, fileEpisode)
-	var destination string
-	if filepath.IsAbs(filename) {
-		destination = filename
-	} else {
-		destination = filepath.Join(filepath.Dir(fileEpisode.FullPath), filename)
-	}
-	return mr.RenameFile(ctx, fileEpisode.FullPath, destination, dryrun)
+// patternDir returns the folder written at the start of a pattern, before its
+// first field.
+func patternDir(source string) string {
+	if i := strings.IndexByte(source, '{'); i >= 0 {
+		source = source[:i]
+	}
+	i := strings.LastIndexAny(source, `/\`)
+	if i < 0 {
+		return ""
+	}
+	if i == 0 {
+		return source[:1]
+	}
+	return filepath.Clean(source[:i])
 }
 
-func (mr *MediaRenamer) RenameFile(ctx context.Context, source, destination string, dryrun bool) (string, error) {
-	finalDestination := findUniqueFilename(destination)
+// RenameFile moves source to destination. When destination already exists,
+// the conflict policy decides what happens; the returned result tells which
+// action was taken. Nothing is moved in dry run.
+func (mr *MediaRenamer) RenameFile(ctx context.Context, source, destination string, dryrun bool) (RenameResult, error) {
 	log := logger.FromContext(ctx)
-	if dryrun {
-		return finalDestination, nil
+	result := RenameResult{Source: source, Destination: destination, Action: ActionRenamed}
+
+	if existing, err := os.Stat(destination); err == nil {
+		sourceInfo, err := os.Stat(source)
+		switch {
+		case err == nil && os.SameFile(sourceInfo, existing) && filepath.Clean(source) == filepath.Clean(destination):
+			result.Action = ActionUnchanged
+			return result, nil
+		case err == nil && os.SameFile(sourceInfo, existing):
+			// Same file under another case on a case-insensitive filesystem.
+		default:
+			result.Existing = destination
+			if result, err = mr.resolveConflict(ctx, result, dryrun); err != nil {
+				return result, err
+			}
+		}
 	}
-	var err error
-	destDir := filepath.Dir(finalDestination)
-	err = os.MkdirAll(destDir, 0755)
-	if err != nil {
-		log.With("error", err).Error("Error creating destination directory")
-		return "", err
+
+	if dryrun || result.Action == ActionSkipped || result.Action == ActionPending {
+		return result, nil
 	}
-	err = os.Rename(source, finalDestination)
-	if err != nil {
+	if err := applyConflict(result); err != nil {
+		log.With("error", err).Error("Error resolving conflict")
+		return result, err
+	}
+	if err := moveFile(source, result.Destination); err != nil {
 		log.With("error", err).Error("Error renaming file")
-		return "", err
+		return result, err
 	}
-	return finalDestination, nil
+	return result, nil
 }
+
 func (mr *MediaRenamer) SuggestMovies(ctx context.Context, movie mediascanner.Movie, maxResults int, cfg *config.Config) (suggestions MovieSuggestions, err error) {
 	log := logger.FromContext(ctx).With("movie", movie)
 	suggestions.Movie = movie
+	if !movie.IDs.IsZero() {
+		if byIDs, ok := mr.suggestMovieByIDs(ctx, movie); ok {
+			return byIDs, nil
+		}
+	}
 	maxResults = int(math.Max(math.Min(float64(maxResults), 100), 1))
 	movies, err := mr.movieClient.SearchMovie(ctx, movie.Name, movie.Year, 1)
 	if err != nil {
 		log.With("error", err).Error("Error searching movie")
 		return
 	}
+	search := MovieSearch{Query: movie.Name, Year: movie.Year}
 	if movies.Totals == 0 {
-		log.Warnf("No movie found for %s", movie.Name)
-		err = errors.New("no movie found")
-		return
-	}
-	suggestions.SuggestedMovies = movies.Movies
-	if len(suggestions.SuggestedMovies) > maxResults {
-		suggestions.SuggestedMovies = suggestions.SuggestedMovies[:maxResults]
+		var relaxed relaxedQuery
+		if movies, relaxed, err = mr.relaxMovieSearch(ctx, movie.Name, movie.Year); err != nil {
+			log.With("error", err).Error("Error searching movie")
+			return
+		}
+		if movies.Totals == 0 {
+			log.Warnf("No movie found for %s", movie.Name)
+			err = errors.New("no movie found")
+			return
+		}
+		search.Query, search.Year = relaxed.query, relaxed.year
+		suggestions.Strategy = relaxed.strategy
 	}
+	found := mr.searchMoviePages(ctx, &search, movies)
+	suggestions.Search = search
+	suggestions = mr.suggestMovies(ctx, suggestions, preferTitleMatches(search.Query, found, movieTitles), maxResults)
 	return
 }
 
 func (mr *MediaRenamer) SuggestEpisodes(ctx context.Context, episode mediascanner.Episode, maxResults int, cfg *config.Config) (suggestions EpisodeSuggestions, err error) {
 	log := logger.FromContext(ctx).With("episode", episode)
 	suggestions.Episode = episode
+	if !episode.IDs.IsZero() {
+		if byIDs, ok := mr.suggestEpisodeByIDs(ctx, episode); ok {
+			return byIDs, nil
+		}
+	}
 	log.Debugf("Plan A: Searching for show with name '%s'", episode.Name)
 	suggestions, err = mr.searchEpisodesByName(ctx, episode, maxResults)
 	if err == nil && len(suggestions.SuggestedEpisodes) > 0 {
@@ -170,11 +322,13 @@ func (mr *MediaRenamer) SuggestEpisodes(ctx context.Context, episode mediascanne
 		return fallbackSuggestions, nil
 	}
 	log.Errorf("Plan B also failed. Could not find any match.")
+	if errors.Is(fallbackErr, mediadata.ErrNeedsOnlineLookup) {
+		return suggestions, fallbackErr
+	}
 	return suggestions, err
 }
 
 func (mr *MediaRenamer) searchEpisodesByName(ctx context.Context, episode mediascanner.Episode, maxResults int) (suggestions EpisodeSuggestions, err error) {
-	log := logger.FromContext(ctx)
 	suggestions.Episode = episode
 	tvShows, err := mr.tvShowClient.SearchTvShow(ctx, episode.Name, 0, 1)
 	if err != nil {
@@ -183,24 +337,52 @@ func (mr *MediaRenamer) searchEpisodesByName(ctx context.Context, episode medias
 	if tvShows.Totals == 0 {
 		return suggestions, errors.New("no tv show found")
 	}
-	for _, tvShow := range tvShows.TvShows {
-		foundEpisode, err := mr.tvShowClient.GetEpisode(ctx, tvShow.ID, episode.Season, episode.Episode)
+	if suggestions.SuggestedEpisodes, err = mr.findEpisodes(ctx, episode, mr.searchTvShowPages(ctx, episode.Name, tvShows)); err != nil {
+		return suggestions, err
+	}
+	suggestions.SuggestedEpisodes = preferTitleMatches(episode.Name, suggestions.SuggestedEpisodes, tvShowTitles)
+	suggestions.SuggestedEpisodes = mr.rankEpisodesByRuntime(ctx, episode, suggestions.SuggestedEpisodes)
+	if len(suggestions.SuggestedEpisodes) > maxResults {
+		suggestions.SuggestedEpisodes = suggestions.SuggestedEpisodes[:maxResults]
+	}
+	for i := range suggestions.SuggestedEpisodes {
+		suggestions.SuggestedEpisodes[i].TvShow = mr.localizeTvShow(ctx, suggestions.SuggestedEpisodes[i].TvShow)
+	}
+	return suggestions, nil
+}
+
+// findEpisodes returns the episode of the file in each of the shows having
+// it.
+func (mr *MediaRenamer) findEpisodes(ctx context.Context, episode mediascanner.Episode, tvShows []mediadata.TvShow) ([]SuggestedEpisode, error) {
+	log := logger.FromContext(ctx)
+	var found []SuggestedEpisode
+	var offlineErr error
+	for _, tvShow := range tvShows {
+		seasonEpisodes, err := mr.tvShowClient.GetSeasonEpisodes(ctx, tvShow.ID, episode.Season)
 		if err != nil {
-			log.Debugf("Could not find S%02dE%02d in show '%s'. Error: %v", episode.Season, episode.Episode, tvShow.Title, err)
+			log.Debugf("Could not get season %d of show '%s'. Error: %v", episode.Season, tvShow.Title, err)
+			if errors.Is(err, mediadata.ErrNeedsOnlineLookup) {
+				offlineErr = err
+			}
+			continue
+		}
+		foundEpisode, ok := mediadata.FindEpisode(seasonEpisodes, episode.Episode)
+		if !ok {
+			log.Debugf("Could not find S%02dE%02d in show '%s'", episode.Season, episode.Episode, tvShow.Title)
 			continue
 		}
-		suggestions.SuggestedEpisodes = append(suggestions.SuggestedEpisodes, SuggestedEpisode{
+		found = append(found, SuggestedEpisode{
 			TvShow:  tvShow,
 			Episode: foundEpisode,
 		})
 	}
-	if len(suggestions.SuggestedEpisodes) == 0 {
-		return suggestions, errors.New("show found, but specific episode not found")
-	}
-	if len(suggestions.SuggestedEpisodes) > maxResults {
-		suggestions.SuggestedEpisodes = suggestions.SuggestedEpisodes[:maxResults]
+	if len(found) == 0 {
+		if offlineErr != nil {
+			return nil, offlineErr
+		}
+		return nil, errors.New("show found, but specific episode not found")
 	}
-	return suggestions, nil
+	return found, nil
 }
 
 func (mr *MediaRenamer) getMoviesSuggestions(ctx context.Context, movies []mediascanner.Movie, maxResults int, cfg *config.Config, log *zap.SugaredLogger, callback ...FindMovieSuggestionCallback) (movieSuggestion []MovieSuggestions) {
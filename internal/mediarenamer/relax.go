@@ -0,0 +1,142 @@
+package mediarenamer
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/pkg/config"
+	"github.com/nouuu/gonamer/pkg/logger"
+	"github.com/nouuu/gonamer/pkg/normalize"
+)
+
+// akaRegex splits the names listing several titles, as in
+// "Leon aka The Professional".
+var akaRegex = regexp.MustCompile(`(?i)\s+a\.?k\.?a\.?\s+`)
+
+// maxAlternativeTitleLookups is the number of candidates of each search whose
+// details are fetched to compare their alternative titles with the name.
+const maxAlternativeTitleLookups = 5
+
+type relaxedQuery struct {
+	strategy config.RelaxStep
+	query    string
+	year     int
+}
+
+// relaxedQueries returns the fallback searches of a movie, in the order of
+// steps. Each step relaxes one thing of the original search, so the year is
+// kept unless the step is about the year. The alternative-titles searches
+// drop the year and trailing words, as their results are only kept when one
+// of their alternative titles is the name.
+func relaxedQueries(name string, year int, steps []config.RelaxStep) []relaxedQuery {
+	name = strings.TrimSpace(name)
+	seen := map[relaxedQuery]bool{{query: name, year: year}: true}
+	var queries []relaxedQuery
+	add := func(strategy config.RelaxStep, query string, year int) {
+		query = strings.TrimSpace(query)
+		key := relaxedQuery{query: query, year: year}
+		if strategy == config.RelaxAlternativeTitles {
+			// Filtered results differ from those of the same search.
+			key.strategy = strategy
+		}
+		if query == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		queries = append(queries, relaxedQuery{strategy: strategy, query: query, year: year})
+	}
+
+	for _, step := range steps {
+		switch step {
+		case config.RelaxNoYear:
+			if year != 0 {
+				add(step, name, 0)
+			}
+		case config.RelaxYearRange:
+			if year != 0 {
+				add(step, name, year-1)
+				add(step, name, year+1)
+			}
+		case config.RelaxBeforeDash:
+			if before, _, found := strings.Cut(name, "-"); found {
+				add(step, before, year)
+			}
+		case config.RelaxAkaTitles:
+			if titles := akaRegex.Split(name, -1); len(titles) > 1 {
+				for _, title := range titles {
+					add(step, title, year)
+				}
+			}
+		case config.RelaxAlternativeTitles:
+			if year != 0 {
+				add(step, name, 0)
+			}
+			words := strings.Fields(name)
+			for n := len(words) - 1; n > 1; n-- {
+				add(step, strings.Join(words[:n], " "), 0)
+			}
+		case config.RelaxDropTokens:
+			// A single word left is too loose a search, e.g. "The".
+			words := strings.Fields(name)
+			for n := len(words) - 1; n > 1; n-- {
+				add(step, strings.Join(words[:n], " "), year)
+			}
+		}
+	}
+	return queries
+}
+
+// relaxMovieSearch runs the fallback searches of a movie the search by name
+// and year found nothing for, and returns the first results with the search
+// they were found by.
+func (mr *MediaRenamer) relaxMovieSearch(ctx context.Context, name string, year int) (mediadata.MovieResults, relaxedQuery, error) {
+	log := logger.FromContext(ctx)
+	for _, relaxed := range relaxedQueries(name, year, mr.relaxation) {
+		log.Infof("Retrying search of '%s' with %s: '%s' (%d)", name, relaxed.strategy, relaxed.query, relaxed.year)
+		movies, err := mr.movieClient.SearchMovie(ctx, relaxed.query, relaxed.year, 1)
+		if err != nil {
+			return mediadata.MovieResults{}, relaxed, err
+		}
+		if relaxed.strategy == config.RelaxAlternativeTitles {
+			movies = mr.matchAlternativeTitles(ctx, name, year, movies)
+		}
+		if movies.Totals > 0 {
+			log.Infof("Found %d movies for '%s' with %s", movies.Totals, name, relaxed.strategy)
+			return movies, relaxed, nil
+		}
+	}
+	return mediadata.MovieResults{}, relaxedQuery{}, nil
+}
+
+// matchAlternativeTitles keeps the movies having name among their alternative
+// titles, and released within a year of year when it is known. Only the first
+// candidates are looked up, as each needs its details. The kept movies make a
+// single page, so that no other page of the search is fetched.
+func (mr *MediaRenamer) matchAlternativeTitles(ctx context.Context, name string, year int, movies mediadata.MovieResults) mediadata.MovieResults {
+	log := logger.FromContext(ctx)
+	key := normalize.Key(name)
+	var matches []mediadata.Movie
+	lookups := 0
+	for _, movie := range movies.Movies {
+		if lookups == maxAlternativeTitleLookups {
+			break
+		}
+		if released, err := strconv.Atoi(movie.Year); year != 0 && err == nil && (released < year-1 || released > year+1) {
+			continue
+		}
+		lookups++
+		details, err := mr.movieClient.GetMovieDetails(ctx, movie.ID)
+		if err != nil {
+			log.With("error", err).Warnf("Could not get the alternative titles of '%s'", movie.Title)
+			continue
+		}
+		if slices.ContainsFunc(details.AlternativeTitles, func(title string) bool { return normalize.Key(title) == key }) {
+			matches = append(matches, movie)
+		}
+	}
+	return mediadata.MovieResults{Movies: matches, Totals: int64(len(matches)), ResultsPerPage: int64(len(matches))}
+}
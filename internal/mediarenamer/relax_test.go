@@ -0,0 +1,76 @@
+package mediarenamer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/pkg/config"
+)
+
+func TestRelaxedQueries(t *testing.T) {
+	steps := []config.RelaxStep{config.RelaxNoYear, config.RelaxYearRange, config.RelaxBeforeDash,
+		config.RelaxAkaTitles, config.RelaxAlternativeTitles, config.RelaxDropTokens}
+	got := relaxedQueries("Leon aka The Professional - Extended", 1994, steps)
+	want := []relaxedQuery{
+		{config.RelaxNoYear, "Leon aka The Professional - Extended", 0},
+		{config.RelaxYearRange, "Leon aka The Professional - Extended", 1993},
+		{config.RelaxYearRange, "Leon aka The Professional - Extended", 1995},
+		{config.RelaxBeforeDash, "Leon aka The Professional", 1994},
+		{config.RelaxAkaTitles, "Leon", 1994},
+		{config.RelaxAkaTitles, "The Professional - Extended", 1994},
+		{config.RelaxAlternativeTitles, "Leon aka The Professional - Extended", 0},
+		{config.RelaxAlternativeTitles, "Leon aka The Professional -", 0},
+		{config.RelaxAlternativeTitles, "Leon aka The Professional", 0},
+		{config.RelaxAlternativeTitles, "Leon aka The", 0},
+		{config.RelaxAlternativeTitles, "Leon aka", 0},
+		{config.RelaxDropTokens, "Leon aka The Professional -", 1994},
+		{config.RelaxDropTokens, "Leon aka The", 1994},
+		{config.RelaxDropTokens, "Leon aka", 1994},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("relaxedQueries() =\n%v\nwant\n%v", got, want)
+	}
+
+	if got := relaxedQueries("Heat", 0, steps); len(got) != 0 {
+		t.Errorf("relaxedQueries() without year = %v, want none", got)
+	}
+	if got := relaxedQueries("The Matrix", 1999, []config.RelaxStep{config.RelaxDropTokens}); len(got) != 0 {
+		t.Errorf("relaxedQueries() of two words = %v, want none", got)
+	}
+	if got := relaxedQueries("The Matrix", 1999, nil); len(got) != 0 {
+		t.Errorf("relaxedQueries() without steps = %v, want none", got)
+	}
+}
+
+// detailsClient returns the details of its movies, by ID.
+type detailsClient struct {
+	mediadata.MovieClient
+	details map[string]mediadata.MovieDetails
+}
+
+func (c detailsClient) GetMovieDetails(_ context.Context, id string) (mediadata.MovieDetails, error) {
+	return c.details[id], nil
+}
+
+func TestMatchAlternativeTitles(t *testing.T) {
+	mr := &MediaRenamer{movieClient: detailsClient{details: map[string]mediadata.MovieDetails{
+		"1": {AlternativeTitles: []string{"The Professional"}},
+		"2": {AlternativeTitles: []string{"Léon: The Professional"}},
+		"3": {AlternativeTitles: []string{"Leon: The Professional"}},
+	}}}
+	movies := mediadata.MovieResults{Movies: []mediadata.Movie{
+		{ID: "1", Year: "1994"},
+		{ID: "2", Year: "1994"},
+		{ID: "3", Year: "2010"},
+	}, Totals: 40, ResultsPerPage: 20}
+
+	got := mr.matchAlternativeTitles(context.Background(), "Leon The Professional", 1994, movies)
+	if len(got.Movies) != 1 || got.Movies[0].ID != "2" || got.Totals != 1 || got.ResultsPerPage != 1 {
+		t.Errorf("matchAlternativeTitles() = %+v, want only movie 2 on a single page", got)
+	}
+	if got := mr.matchAlternativeTitles(context.Background(), "Leon The Professional", 0, movies); len(got.Movies) != 2 {
+		t.Errorf("matchAlternativeTitles() without year = %+v, want movies 2 and 3", got)
+	}
+}
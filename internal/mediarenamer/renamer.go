@@ -2,58 +2,192 @@ package mediarenamer
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/nouuu/gonamer/internal/mediadata"
 	"github.com/nouuu/gonamer/internal/mediascanner"
+	"github.com/nouuu/gonamer/pkg/pattern"
+	"github.com/nouuu/gonamer/pkg/sanitize"
+	"github.com/nouuu/gonamer/pkg/sortname"
 )
 
-type Field string
+func ParseMoviePattern(moviePattern string) (*pattern.Template, error) {
+	tmpl, err := pattern.Parse(moviePattern, pattern.MovieFields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid movie pattern %q: %w", moviePattern, err)
+	}
+	return tmpl, nil
+}
 
-const (
-	FieldName         Field = "{name}"
-	FieldYear         Field = "{year}"
-	FieldDate         Field = "{date}"
-	FieldExt          Field = "{extension}"
-	FieldSeason       Field = "{season}"
-	FieldEpisode      Field = "{episode}"
-	FieldEpisodeTitle Field = "{episode_title}"
-)
+func ParseEpisodePattern(showPattern string) (*pattern.Template, error) {
+	tmpl, err := pattern.Parse(showPattern, pattern.EpisodeFields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tv show pattern %q: %w", showPattern, err)
+	}
+	return tmpl, nil
+}
+
+// GenerateMovieFilename renders the movie pattern and makes it safe for the
+// filesystem with s. Detail fields render empty when movie only carries the
+// search result.
+func GenerateMovieFilename(tmpl *pattern.Template, movie mediadata.MovieDetails, fileMovie mediascanner.Movie, s *sanitize.Sanitizer, sorter *sortname.Sorter) string {
+	sortName := sorter.SortName(movie.Title)
+	return render(tmpl, withTechnical(pattern.Values{
+		pattern.FieldName:             pattern.Text(movie.Title),
+		pattern.FieldSortName:         pattern.Text(sortName),
+		pattern.FieldInitial:          pattern.Text(sortname.Initial(sortName)),
+		pattern.FieldYear:             pattern.Text(movie.Year),
+		pattern.FieldDate:             pattern.Text(movie.ReleaseDate),
+		pattern.FieldExt:              pattern.Text(fileMovie.Extension),
+		pattern.FieldTmdbID:           pattern.Text(movie.ID),
+		pattern.FieldImdbID:           pattern.Text(movie.ImdbID),
+		pattern.FieldOriginalTitle:    pattern.Text(movie.OriginalTitle),
+		pattern.FieldOriginalLanguage: pattern.Text(movie.OriginalLanguage),
+		pattern.FieldCountry:          pattern.Text(firstCountry(movie.OriginCountry, movie.ProductionCountries)),
+		pattern.FieldGenre:            pattern.Text(firstGenre(movie.Genres)),
+		pattern.FieldStudio:           pattern.Text(firstStudio(movie.Studio)),
+		pattern.FieldRuntime:          optionalNumber(movie.Runtime),
+		pattern.FieldRating:           rating(movie.Rating),
+		pattern.FieldCertification:    pattern.Text(movie.Certification),
+		pattern.FieldOriginalFilename: pattern.Text(strings.TrimSuffix(fileMovie.OriginalFilename, fileMovie.Extension)),
+		pattern.FieldEdition:          pattern.Text(fileMovie.Edition),
+		pattern.FieldPart:             optionalNumber(fileMovie.Part),
+		pattern.FieldExtra:            pattern.Text(fileMovie.Extra),
+		pattern.FieldCollection:       pattern.Text(movie.Collection.Name),
+	}, fileMovie.Quality, fileMovie.Media), s)
+}
+
+// GenerateEpisodeFilename renders the tv show pattern and makes it safe for
+// the filesystem with s. Detail fields render empty when show only carries the
+// search result. season holds the episodes of the season, if known, which size
+// the episode numbers along with the show details.
+func GenerateEpisodeFilename(tmpl *pattern.Template, show mediadata.TvShowDetails, episode mediadata.Episode, season []mediadata.Episode, fileEpisode mediascanner.Episode, s *sanitize.Sanitizer, sorter *sortname.Sorter) string {
+	network := pattern.Text(firstStudio(show.Studio))
+	seasonWidth, episodeWidth := numberWidths(show, season, episode.SeasonNumber)
+	sortName := sorter.SortName(show.Title)
+	return render(tmpl, withTechnical(pattern.Values{
+		pattern.FieldName:             pattern.Text(show.Title),
+		pattern.FieldSortName:         pattern.Text(sortName),
+		pattern.FieldInitial:          pattern.Text(sortname.Initial(sortName)),
+		pattern.FieldYear:             pattern.Text(show.Year),
+		pattern.FieldSeason:           pattern.PaddedNumber(episode.SeasonNumber, seasonWidth),
+		pattern.FieldEpisode:          pattern.PaddedNumber(episode.EpisodeNumber, episodeWidth),
+		pattern.FieldEpisodeEnd:       pattern.PaddedNumber(fileEpisode.EpisodeEnd, episodeWidth),
+		pattern.FieldEpisodeTitle:     pattern.Text(episode.Name),
+		pattern.FieldExt:              pattern.Text(fileEpisode.Extension),
+		pattern.FieldTmdbID:           pattern.Text(show.ID),
+		pattern.FieldImdbID:           pattern.Text(show.ImdbID),
+		pattern.FieldTvdbID:           pattern.Text(show.TvdbID),
+		pattern.FieldOriginalTitle:    pattern.Text(show.OriginalTitle),
+		pattern.FieldOriginalLanguage: pattern.Text(show.OriginalLanguage),
+		pattern.FieldCountry:          pattern.Text(firstCountry(show.OriginCountry, show.ProductionCountries)),
+		pattern.FieldGenre:            pattern.Text(firstGenre(show.Genres)),
+		pattern.FieldStudio:           network,
+		pattern.FieldNetwork:          network,
+		pattern.FieldRuntime:          optionalNumber(show.EpisodeRuntime),
+		pattern.FieldRating:           rating(show.Rating),
+		pattern.FieldCertification:    pattern.Text(show.Certification),
+		pattern.FieldShowYear:         pattern.Text(show.Year),
+		pattern.FieldEpisodeAirDate:   pattern.Text(episode.AirDate),
+		pattern.FieldOriginalFilename: pattern.Text(strings.TrimSuffix(fileEpisode.OriginalFilename, fileEpisode.Extension)),
+	}, fileEpisode.Quality, fileEpisode.Media), s)
+}
+
+// render executes tmpl with every field value kept within one path component,
+// then cleans the resulting path. Separators written in the pattern itself
+// still create folders.
+func render(tmpl *pattern.Template, values pattern.Values, s *sanitize.Sanitizer) string {
+	return s.Path(tmpl.ExecuteEscaped(values, s.Value))
+}
+
+// defaultNumberWidth is the zero-padding of season and episode numbers, as in
+// "01x05".
+const defaultNumberWidth = 2
+
+// numberWidths returns the zero-padding of season and episode numbers, which
+// grows past defaultNumberWidth so that every episode of a show with a
+// 100+ episodes season is numbered alike ("S01E005" ... "S01E100"). The
+// episodes of the season are enough to size the episode numbers; the show
+// details, when fetched, also account for the other seasons.
+func numberWidths(show mediadata.TvShowDetails, season []mediadata.Episode, seasonNumber int) (seasonWidth, episodeWidth int) {
+	maxEpisodes := 0
+	for _, s := range show.Seasons {
+		maxEpisodes = max(maxEpisodes, s.EpisodeCount)
+	}
+	for _, e := range season {
+		maxEpisodes = max(maxEpisodes, e.EpisodeNumber)
+	}
+	return max(defaultNumberWidth, len(strconv.Itoa(max(show.SeasonCount, seasonNumber)))),
+		max(defaultNumberWidth, len(strconv.Itoa(maxEpisodes)))
+}
+
+// withTechnical adds the technical fields. Values read from the container
+// headers win over the release name, which may lie.
+func withTechnical(values pattern.Values, quality mediascanner.Quality, media mediascanner.MediaInfo) pattern.Values {
+	resolution, vcodec, audio := quality.Resolution, quality.VideoCodec, quality.Audio()
+	if r := media.Resolution(); r != "" {
+		resolution = r
+	}
+	if media.VideoCodec != "" {
+		vcodec = media.VideoCodec
+	}
+	if track, ok := media.MainAudio(); ok && track.Codec != "" {
+		audio = strings.TrimSpace(track.Codec + " " + track.Layout())
+		// Atmos is an extension of TrueHD/EAC3 streams only visible in the name.
+		if strings.Contains(quality.AudioCodec, "Atmos") {
+			audio = strings.TrimSpace(track.Codec + " Atmos " + track.Layout())
+		}
+	}
+
+	values[pattern.FieldResolution] = pattern.Text(resolution)
+	values[pattern.FieldSource] = pattern.Text(quality.Source)
+	values[pattern.FieldVideoCodec] = pattern.Text(vcodec)
+	values[pattern.FieldHDR] = pattern.Text(quality.HDR)
+	values[pattern.FieldAudio] = pattern.Text(audio)
+	values[pattern.FieldGroup] = pattern.Text(quality.ReleaseGroup)
+	values[pattern.FieldDuration] = optionalNumber(media.Runtime())
+	values[pattern.FieldAudioLanguages] = pattern.Text(strings.Join(media.AudioLanguages(), "+"))
+	values[pattern.FieldSubtitles] = pattern.Text(strings.Join(media.SubtitleLanguages(), "+"))
+	return values
+}
 
-func GenerateMovieFilename(pattern string, movie mediadata.Movie, fileMovie mediascanner.Movie) string {
-	//return fmt.Sprintf("%s - %s%s", movie.Title, movie.Year, fileMovie.Extension)
-	filename := pattern
-	filename = replaceField(filename, FieldName, movie.Title)
-	filename = replaceField(filename, FieldYear, movie.Year)
-	filename = replaceField(filename, FieldDate, movie.ReleaseDate)
-	filename = replaceField(filename, FieldExt, fileMovie.Extension)
-	return filename
+func firstGenre(genres []mediadata.Genre) string {
+	if len(genres) == 0 {
+		return ""
+	}
+	return genres[0].Name
 }
 
-func GenerateEpisodeFilename(pattern string, show mediadata.TvShow, episode mediadata.Episode, fileEpisode mediascanner.Episode) string {
-	filename := pattern
-	filename = replaceField(filename, FieldName, show.Title)
-	filename = replaceField(filename, FieldYear, show.Year)
-	filename = replaceFieldInt(filename, FieldSeason, episode.SeasonNumber)
-	filename = replaceFieldInt(filename, FieldEpisode, episode.EpisodeNumber)
-	filename = replaceField(filename, FieldEpisodeTitle, episode.Name)
-	filename = replaceField(filename, FieldExt, fileEpisode.Extension)
-	return filename
+func firstStudio(studios []mediadata.Studio) string {
+	if len(studios) == 0 {
+		return ""
+	}
+	return studios[0].Name
 }
 
-/*func _generateDefaultMovieFilename(fileMovie mediascanner.Movie) string {
-	filename := fileMovie.Name
-	if fileMovie.Year != 0 {
-		filename += " - " + strconv.Itoa(fileMovie.Year)
+// firstCountry returns the first origin country, or the first production
+// country when TMDB has no origin for the movie.
+func firstCountry(origin, production []string) string {
+	if len(origin) > 0 {
+		return origin[0]
+	}
+	if len(production) > 0 {
+		return production[0]
 	}
-	filename += fileMovie.Extension
-	return filename
-}*/
+	return ""
+}
 
-func replaceField(pattern string, field Field, value string) string {
-	return strings.ReplaceAll(pattern, string(field), value)
+func optionalNumber(n int) pattern.Value {
+	if n == 0 {
+		return pattern.Text("")
+	}
+	return pattern.Number(n)
 }
 
-func replaceFieldInt(pattern string, field Field, value int) string {
-	return strings.ReplaceAll(pattern, string(field), fmt.Sprintf("%02d", value))
+func rating(r float32) pattern.Value {
+	if r == 0 {
+		return pattern.Text("")
+	}
+	return pattern.Text(fmt.Sprintf("%.1f", r))
 }
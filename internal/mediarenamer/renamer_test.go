@@ -0,0 +1,92 @@
+package mediarenamer
+
+import (
+	"testing"
+
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/internal/mediascanner"
+	"github.com/nouuu/gonamer/pkg/sanitize"
+	"github.com/nouuu/gonamer/pkg/sortname"
+)
+
+func TestNumberWidths(t *testing.T) {
+	tests := []struct {
+		name                      string
+		show                      mediadata.TvShowDetails
+		season                    []mediadata.Episode
+		seasonNumber              int
+		seasonWidth, episodeWidth int
+	}{
+		{"no details", mediadata.TvShowDetails{}, nil, 1, 2, 2},
+		{"short show", mediadata.TvShowDetails{SeasonCount: 3, Seasons: []mediadata.Season{{EpisodeCount: 10}, {EpisodeCount: 12}}}, nil, 1, 2, 2},
+		{"long season", mediadata.TvShowDetails{SeasonCount: 1, Seasons: []mediadata.Season{{EpisodeCount: 8}, {EpisodeCount: 1100}}}, nil, 1, 2, 4},
+		{"many seasons", mediadata.TvShowDetails{SeasonCount: 120}, nil, 1, 3, 2},
+		{"long season list", mediadata.TvShowDetails{}, []mediadata.Episode{{EpisodeNumber: 1}, {EpisodeNumber: 150}}, 1, 2, 3},
+		{"high season number", mediadata.TvShowDetails{}, nil, 2004, 4, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			season, episode := numberWidths(tt.show, tt.season, tt.seasonNumber)
+			if season != tt.seasonWidth || episode != tt.episodeWidth {
+				t.Errorf("numberWidths() = %d, %d, want %d, %d", season, episode, tt.seasonWidth, tt.episodeWidth)
+			}
+		})
+	}
+}
+
+func TestGenerateMovieFilenameEdition(t *testing.T) {
+	tmpl, err := ParseMoviePattern("{name} ({year}){if edition} {{edition-{edition}}}{end}{if part} - pt{part}{end}{extension}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	movie := mediadata.MovieDetails{Movie: mediadata.Movie{Title: "Blade Runner", Year: "1982"}}
+	s := sanitize.New(sanitize.Windows, 0)
+
+	tests := []struct {
+		file mediascanner.Movie
+		want string
+	}{
+		{mediascanner.Movie{Extension: ".mkv"}, "Blade Runner (1982).mkv"},
+		{mediascanner.Movie{Extension: ".mkv", Edition: "Director's Cut"}, "Blade Runner (1982) {edition-Director's Cut}.mkv"},
+		{mediascanner.Movie{Extension: ".avi", Part: 2}, "Blade Runner (1982) - pt2.avi"},
+	}
+	for _, tt := range tests {
+		if got := GenerateMovieFilename(tmpl, movie, tt.file, s, sortname.New("en", nil)); got != tt.want {
+			t.Errorf("GenerateMovieFilename() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestGenerateMovieFilenameSortName(t *testing.T) {
+	tmpl, err := ParseMoviePattern("Movies/{initial}/{sort_name} ({year})/{name}{extension}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	movie := mediadata.MovieDetails{Movie: mediadata.Movie{Title: "The Matrix", Year: "1999"}}
+	got := GenerateMovieFilename(tmpl, movie, mediascanner.Movie{Extension: ".mkv"}, sanitize.New(sanitize.Windows, 0), sortname.New("en-US", nil))
+	if want := "Movies/M/Matrix, The (1999)/The Matrix.mkv"; got != want {
+		t.Errorf("GenerateMovieFilename() = %q, want %q", got, want)
+	}
+}
+
+func TestGenerateMovieFilenameCollection(t *testing.T) {
+	tmpl, err := ParseMoviePattern("{if collection}{collection}/{end}{name} ({year}){extension}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, sorter := sanitize.New(sanitize.Windows, 0), sortname.New("en", nil)
+
+	tests := []struct {
+		movie mediadata.MovieDetails
+		want  string
+	}{
+		{mediadata.MovieDetails{Movie: mediadata.Movie{Title: "The Two Towers", Year: "2002"}, Collection: mediadata.Collection{Name: "The Lord of the Rings Collection"}},
+			"The Lord of the Rings Collection/The Two Towers (2002).mkv"},
+		{mediadata.MovieDetails{Movie: mediadata.Movie{Title: "Heat", Year: "1995"}}, "Heat (1995).mkv"},
+	}
+	for _, tt := range tests {
+		if got := GenerateMovieFilename(tmpl, tt.movie, mediascanner.Movie{Extension: ".mkv"}, s, sorter); got != tt.want {
+			t.Errorf("GenerateMovieFilename() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package mediarenamer
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/pkg/config"
+)
+
+// routeItem is the metadata routing rules are matched against.
+type routeItem struct {
+	kind          config.MediaType
+	genres        []string
+	language      string
+	certification string
+	keywords      []string
+	rating        float32
+	year          int
+	runtime       int
+}
+
+func movieRouteItem(movie mediadata.MovieDetails) routeItem {
+	year, _ := strconv.Atoi(movie.Year)
+	return routeItem{
+		kind:          config.Movie,
+		genres:        genreNames(movie.Genres),
+		language:      movie.OriginalLanguage,
+		certification: movie.Certification,
+		keywords:      movie.Keywords,
+		rating:        movie.Rating,
+		year:          year,
+		runtime:       movie.Runtime,
+	}
+}
+
+func episodeRouteItem(show mediadata.TvShowDetails, episode mediadata.Episode) routeItem {
+	year, _ := strconv.Atoi(show.Year)
+	runtime := episode.Runtime
+	if runtime == 0 {
+		runtime = show.EpisodeRuntime
+	}
+	return routeItem{
+		kind:          config.TvShow,
+		genres:        genreNames(show.Genres),
+		language:      show.OriginalLanguage,
+		certification: show.Certification,
+		keywords:      show.Keywords,
+		rating:        show.Rating,
+		year:          year,
+		runtime:       runtime,
+	}
+}
+
+// hasRoutes reports whether a rule may apply to kind, in which case the
+// details are needed before renaming.
+func (mr *MediaRenamer) hasRoutes(kind config.MediaType) bool {
+	for _, rule := range mr.routes {
+		if rule.Match.Type == "" || rule.Match.Type == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// route returns the first rule matching item.
+func (mr *MediaRenamer) route(item routeItem) (config.RouteRule, bool) {
+	for _, rule := range mr.routes {
+		if matchRoute(rule.Match, item) {
+			return rule, true
+		}
+	}
+	return config.RouteRule{}, false
+}
+
+func matchRoute(match config.RouteMatch, item routeItem) bool {
+	return (match.Type == "" || match.Type == item.kind) &&
+		matchAny(match.Genres, item.genres...) &&
+		matchAny(match.OriginalLanguage, item.language) &&
+		matchAny(match.Certifications, item.certification) &&
+		matchAny(match.Keywords, item.keywords...) &&
+		inRange(float64(item.rating), float64(match.MinRating), float64(match.MaxRating)) &&
+		inRange(float64(item.year), float64(match.MinYear), float64(match.MaxYear)) &&
+		inRange(float64(item.runtime), float64(match.MinRuntime), float64(match.MaxRuntime))
+}
+
+// matchAny reports whether values holds one of the wanted values, ignoring
+// case, and none of those prefixed with "!". An empty list always matches,
+// while unknown values never do.
+func matchAny(wanted []string, values ...string) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	if !slices.ContainsFunc(values, func(v string) bool { return v != "" }) {
+		return false
+	}
+	matched, hasWanted := false, false
+	for _, w := range wanted {
+		if excluded, ok := strings.CutPrefix(w, "!"); ok {
+			if containsFold(values, excluded) {
+				return false
+			}
+			continue
+		}
+		hasWanted = true
+		matched = matched || containsFold(values, w)
+	}
+	return matched || !hasWanted
+}
+
+func containsFold(values []string, s string) bool {
+	return slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, s) })
+}
+
+// inRange reports whether value is within the bounds, a zero bound being
+// ignored. Unknown (zero) values never match a bound.
+func inRange(value, minValue, maxValue float64) bool {
+	if minValue == 0 && maxValue == 0 {
+		return true
+	}
+	return value != 0 && (minValue == 0 || value >= minValue) && (maxValue == 0 || value <= maxValue)
+}
+
+func genreNames(genres []mediadata.Genre) []string {
+	names := make([]string, 0, len(genres))
+	for _, genre := range genres {
+		names = append(names, genre.Name)
+	}
+	return names
+}
@@ -0,0 +1,73 @@
+package mediarenamer
+
+import (
+	"testing"
+
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/pkg/config"
+	"github.com/nouuu/gonamer/pkg/pattern"
+)
+
+func TestRoute(t *testing.T) {
+	mr := NewMediaRenamer(nil, nil, config.RenamerConfig{Routes: []config.RouteRule{
+		{Name: "documentaries", Match: config.RouteMatch{Genres: []string{"Documentary"}}, Root: "/docs"},
+		{Name: "kids", Match: config.RouteMatch{Type: config.Movie, Genres: []string{"animation", "family"}, Certifications: []string{"G", "PG"}}, Root: "/kids"},
+		{Name: "foreign", Match: config.RouteMatch{OriginalLanguage: []string{"!en"}}, Root: "/foreign"},
+		{Name: "classics", Match: config.RouteMatch{MaxYear: 1969, MinRating: 7.5}, Root: "/classics"},
+	}})
+
+	tests := []struct {
+		name  string
+		movie mediadata.MovieDetails
+		want  string
+	}{
+		{"documentary", mediadata.MovieDetails{Movie: mediadata.Movie{OriginalLanguage: "fr"}, Genres: []mediadata.Genre{{Name: "Documentary"}}}, "documentaries"},
+		{"kids", mediadata.MovieDetails{Movie: mediadata.Movie{OriginalLanguage: "en"}, Genres: []mediadata.Genre{{Name: "Family"}}, Certification: "PG"}, "kids"},
+		{"rated animation", mediadata.MovieDetails{Movie: mediadata.Movie{OriginalLanguage: "en"}, Genres: []mediadata.Genre{{Name: "Animation"}}, Certification: "R"}, ""},
+		{"foreign", mediadata.MovieDetails{Movie: mediadata.Movie{OriginalLanguage: "ja"}}, "foreign"},
+		{"unknown language", mediadata.MovieDetails{}, ""},
+		{"classic", mediadata.MovieDetails{Movie: mediadata.Movie{Year: "1954", Rating: 8.5, OriginalLanguage: "en"}}, "classics"},
+		{"weak classic", mediadata.MovieDetails{Movie: mediadata.Movie{Year: "1954", Rating: 6, OriginalLanguage: "en"}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, _ := mr.route(movieRouteItem(tt.movie))
+			if rule.Name != tt.want {
+				t.Errorf("route() = %q, want %q", rule.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestDestination(t *testing.T) {
+	mr := NewMediaRenamer(nil, nil, config.RenamerConfig{})
+	absolute, err := ParseMoviePattern("/media/Movies/{name} ({year})/{name} ({year}){extension}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	relative, err := ParseMoviePattern("{name} ({year}){extension}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kids := config.RouteRule{Name: "kids", Root: "/media/Kids"}
+
+	tests := []struct {
+		name     string
+		filename string
+		tmpl     *pattern.Template
+		rule     config.RouteRule
+		want     string
+	}{
+		{"absolute", "/media/Movies/Up (2009)/Up (2009).mkv", absolute, config.RouteRule{}, "/media/Movies/Up (2009)/Up (2009).mkv"},
+		{"absolute routed", "/media/Movies/Up (2009)/Up (2009).mkv", absolute, kids, "/media/Kids/Up (2009)/Up (2009).mkv"},
+		{"relative", "Up (2009).mkv", relative, config.RouteRule{}, "/downloads/Up (2009).mkv"},
+		{"relative routed", "Up (2009).mkv", relative, kids, "/media/Kids/Up (2009).mkv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mr.destination("/downloads/up.mkv", tt.filename, tt.tmpl, tt.rule); got != tt.want {
+				t.Errorf("destination() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package mediarenamer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/internal/mediascanner"
+	"github.com/nouuu/gonamer/pkg/logger"
+)
+
+// Runtime ratios between the probed file duration and the TMDB runtime.
+// Releases trim credits or add a few minutes of extended cut, so only
+// candidates within closeRuntimeRatio are trusted more; past
+// mismatchRuntimeRatio the candidate is flagged, and past
+// implausibleRuntimeRatio it is very likely a different work, e.g. a 22
+// minutes file against a 2 hours movie.
+const (
+	closeRuntimeRatio       = 1.1
+	mismatchRuntimeRatio    = 1.35
+	implausibleRuntimeRatio = 2.0
+)
+
+// Score adjustments applied on top of the search rank, where each position is
+// worth one point.
+const (
+	closeRuntimeBonus         = 1.5
+	mismatchRuntimePenalty    = 2
+	implausibleRuntimePenalty = 100
+)
+
+// RuntimeMismatch flags a candidate whose runtime is far from the file duration.
+type RuntimeMismatch struct {
+	FileRuntime      int
+	CandidateRuntime int
+}
+
+func (m RuntimeMismatch) String() string {
+	return fmt.Sprintf("file is %d min, candidate is %d min", m.FileRuntime, m.CandidateRuntime)
+}
+
+// runtimeMismatch returns the mismatch between both runtimes, or nil when
+// either is unknown or they are close enough.
+func runtimeMismatch(fileRuntime, candidateRuntime int) *RuntimeMismatch {
+	if fileRuntime <= 0 || candidateRuntime <= 0 || runtimeRatio(fileRuntime, candidateRuntime) <= mismatchRuntimeRatio {
+		return nil
+	}
+	return &RuntimeMismatch{FileRuntime: fileRuntime, CandidateRuntime: candidateRuntime}
+}
+
+// runtimeOrder returns the indexes of the candidates sorted by their search
+// rank adjusted by how close their runtime is to the file duration. Unknown
+// runtimes (0) keep their rank unchanged.
+func runtimeOrder(fileRuntime int, runtimes []int) []int {
+	scores := make([]float64, len(runtimes))
+	order := make([]int, len(runtimes))
+	for i, runtime := range runtimes {
+		order[i] = i
+		scores[i] = -float64(i)
+		if fileRuntime <= 0 || runtime <= 0 {
+			continue
+		}
+		switch ratio := runtimeRatio(fileRuntime, runtime); {
+		case ratio > implausibleRuntimeRatio:
+			scores[i] -= implausibleRuntimePenalty
+		case ratio > mismatchRuntimeRatio:
+			scores[i] -= mismatchRuntimePenalty
+		case ratio <= closeRuntimeRatio:
+			scores[i] += closeRuntimeBonus
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scores[order[a]] > scores[order[b]]
+	})
+	return order
+}
+
+// rankMoviesByRuntime reorders the candidates by how close their TMDB runtime
+// is to the probed file duration and returns the mismatching ones by ID.
+// Details are only fetched when the file duration is known. The parts of a
+// split movie and its extras only hold a fraction of it, whatever its
+// runtime, so they are left in search order.
+func (mr *MediaRenamer) rankMoviesByRuntime(ctx context.Context, movie mediascanner.Movie, candidates []mediadata.Movie) ([]mediadata.Movie, map[string]RuntimeMismatch) {
+	fileRuntime := movie.Media.Runtime()
+	if fileRuntime == 0 || movie.Part != 0 || movie.Extra != "" || len(candidates) == 0 {
+		return candidates, nil
+	}
+	log := logger.FromContext(ctx)
+
+	runtimes := make([]int, len(candidates))
+	mismatches := make(map[string]RuntimeMismatch)
+	for i, candidate := range candidates {
+		details, err := mr.movieClient.GetMovieDetails(ctx, candidate.ID)
+		if err != nil {
+			continue
+		}
+		runtimes[i] = details.Runtime
+		if mismatch := runtimeMismatch(fileRuntime, details.Runtime); mismatch != nil {
+			log.Debugf("Runtime mismatch for '%s': %s", candidate.Title, mismatch)
+			mismatches[candidate.ID] = *mismatch
+		}
+	}
+
+	ranked := make([]mediadata.Movie, 0, len(candidates))
+	for _, i := range runtimeOrder(fileRuntime, runtimes) {
+		ranked = append(ranked, candidates[i])
+	}
+	return ranked, mismatches
+}
+
+// rankEpisodesByRuntime flags and reorders the suggested episodes by how close
+// their runtime is to the probed file duration. The show's typical episode
+// runtime is used when TMDB has none for the episode itself.
+func (mr *MediaRenamer) rankEpisodesByRuntime(ctx context.Context, episode mediascanner.Episode, candidates []SuggestedEpisode) []SuggestedEpisode {
+	fileRuntime := episode.Media.Runtime()
+	if fileRuntime == 0 || len(candidates) == 0 {
+		return candidates
+	}
+	log := logger.FromContext(ctx)
+
+	runtimes := make([]int, len(candidates))
+	for i := range candidates {
+		runtime := candidates[i].Episode.Runtime
+		if runtime == 0 {
+			if details, err := mr.tvShowClient.GetTvShowDetails(ctx, candidates[i].TvShow.ID); err == nil {
+				runtime = details.EpisodeRuntime
+			}
+		}
+		runtimes[i] = runtime
+		candidates[i].RuntimeMismatch = runtimeMismatch(fileRuntime, runtime)
+		if candidates[i].RuntimeMismatch != nil {
+			log.Debugf("Runtime mismatch for '%s' %dx%02d: %s", candidates[i].TvShow.Title,
+				candidates[i].Episode.SeasonNumber, candidates[i].Episode.EpisodeNumber, candidates[i].RuntimeMismatch)
+		}
+	}
+
+	ranked := make([]SuggestedEpisode, 0, len(candidates))
+	for _, i := range runtimeOrder(fileRuntime, runtimes) {
+		ranked = append(ranked, candidates[i])
+	}
+	return ranked
+}
+
+// runtimeRatio returns how many times longer the longest runtime is.
+func runtimeRatio(a, b int) float64 {
+	if a <= 0 || b <= 0 {
+		return 1
+	}
+	if a < b {
+		a, b = b, a
+	}
+	return float64(a) / float64(b)
+}
@@ -0,0 +1,59 @@
+package mediarenamer
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/internal/mediascanner"
+)
+
+func TestRuntimeOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileRuntime int
+		runtimes    []int
+		want        []int
+	}{
+		{"unknown file duration", 0, []int{120, 45, 90}, []int{0, 1, 2}},
+		{"close runtime moves up", 42, []int{100, 44}, []int{1, 0}},
+		{"close runtime doesn't jump far", 42, []int{50, 50, 50, 44}, []int{0, 1, 3, 2}},
+		{"implausible runtime goes last", 128, []int{42, 0, 150}, []int{1, 2, 0}},
+		{"unknown runtimes keep their rank", 90, []int{0, 0}, []int{0, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runtimeOrder(tt.fileRuntime, tt.runtimes); !slices.Equal(got, tt.want) {
+				t.Errorf("runtimeOrder(%d, %v) = %v, want %v", tt.fileRuntime, tt.runtimes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuntimeMismatch(t *testing.T) {
+	if m := runtimeMismatch(42, 128); m == nil || m.String() != "file is 42 min, candidate is 128 min" {
+		t.Errorf("runtimeMismatch(42, 128) = %v", m)
+	}
+	for _, runtimes := range [][2]int{{100, 120}, {0, 128}, {42, 0}} {
+		if m := runtimeMismatch(runtimes[0], runtimes[1]); m != nil {
+			t.Errorf("runtimeMismatch(%d, %d) = %v, want nil", runtimes[0], runtimes[1], m)
+		}
+	}
+}
+
+func TestRankMoviesByRuntimeSkipsParts(t *testing.T) {
+	// Without a movie client, any details lookup would panic.
+	mr := &MediaRenamer{}
+	candidates := []mediadata.Movie{{ID: "1"}, {ID: "2"}}
+	for _, movie := range []mediascanner.Movie{
+		{Part: 1, Media: mediascanner.MediaInfo{Duration: time.Hour}},
+		{Extra: "Trailers", Media: mediascanner.MediaInfo{Duration: 2 * time.Minute}},
+	} {
+		ranked, mismatches := mr.rankMoviesByRuntime(context.Background(), movie, candidates)
+		if !slices.Equal(ranked, candidates) || len(mismatches) != 0 {
+			t.Errorf("rankMoviesByRuntime(%+v) = %v, %v, want the search order without mismatches", movie, ranked, mismatches)
+		}
+	}
+}
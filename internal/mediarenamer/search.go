@@ -0,0 +1,154 @@
+package mediarenamer
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/internal/mediascanner"
+	"github.com/nouuu/gonamer/pkg/logger"
+)
+
+// ErrNoMoreResults is returned when every result of a search was suggested.
+var ErrNoMoreResults = errors.New("no more results")
+
+// MovieSearch is the search suggested movies come from, kept to list more of
+// its results on demand.
+type MovieSearch struct {
+	Query string
+	Year  int
+	// Page is the last page fetched and Pages the number of pages TMDB has.
+	Page  int
+	Pages int
+	// Rest holds the movies fetched but not suggested yet.
+	Rest []mediadata.Movie
+}
+
+// HasMore reports whether the search has results not suggested yet.
+func (s MovieSearch) HasMore() bool {
+	return len(s.Rest) > 0 || s.Page < s.Pages
+}
+
+// searchMoviePages fetches the pages following first up to the search depth,
+// and returns the movies of all of them.
+func (mr *MediaRenamer) searchMoviePages(ctx context.Context, search *MovieSearch, first mediadata.MovieResults) []mediadata.Movie {
+	search.Page, search.Pages = 1, pageCount(first.Totals, first.ResultsPerPage)
+	movies := first.Movies
+	for search.Page < min(mr.searchDepth, search.Pages) {
+		next, err := mr.movieClient.SearchMovie(ctx, search.Query, search.Year, search.Page+1)
+		if err != nil {
+			logger.FromContext(ctx).With("error", err).Warnf("Could not get page %d of '%s'", search.Page+1, search.Query)
+			break
+		}
+		search.Page++
+		movies = appendUnique(movies, next.Movies, movieID)
+	}
+	return movies
+}
+
+// SearchMovies searches query for the movie of a file, as SuggestMovies does
+// for its parsed name.
+func (mr *MediaRenamer) SearchMovies(ctx context.Context, movie mediascanner.Movie, query string, year int, maxResults int) (suggestions MovieSuggestions, err error) {
+	suggestions.Movie = movie
+	movies, err := mr.movieClient.SearchMovie(ctx, query, year, 1)
+	if err != nil {
+		return suggestions, err
+	}
+	suggestions.Search = MovieSearch{Query: query, Year: year}
+	found := mr.searchMoviePages(ctx, &suggestions.Search, movies)
+	return mr.suggestMovies(ctx, suggestions, preferTitleMatches(query, found, movieTitles), maxResults), nil
+}
+
+// MoreMovies suggests the next maxResults movies of the search, fetching its
+// next page once the fetched ones are all suggested.
+func (mr *MediaRenamer) MoreMovies(ctx context.Context, suggestions MovieSuggestions, maxResults int) (MovieSuggestions, error) {
+	search := &suggestions.Search
+	if len(search.Rest) == 0 {
+		if search.Page >= search.Pages {
+			return suggestions, ErrNoMoreResults
+		}
+		next, err := mr.movieClient.SearchMovie(ctx, search.Query, search.Year, search.Page+1)
+		if err != nil {
+			return suggestions, err
+		}
+		search.Page++
+		search.Rest = slices.DeleteFunc(slices.Clone(next.Movies), func(movie mediadata.Movie) bool {
+			return slices.ContainsFunc(suggestions.SuggestedMovies, func(m mediadata.Movie) bool { return m.ID == movie.ID })
+		})
+		if len(search.Rest) == 0 {
+			return mr.MoreMovies(ctx, suggestions, maxResults)
+		}
+	}
+	return mr.suggestMovies(ctx, suggestions, search.Rest, maxResults), nil
+}
+
+// suggestMovies appends the first maxResults candidates, in the naming
+// language and ranked by runtime, to the suggestions and keeps the others in the search rest.
+func (mr *MediaRenamer) suggestMovies(ctx context.Context, suggestions MovieSuggestions, candidates []mediadata.Movie, maxResults int) MovieSuggestions {
+	batch, rest := candidates, []mediadata.Movie(nil)
+	if len(candidates) > maxResults {
+		batch, rest = candidates[:maxResults], slices.Clone(candidates[maxResults:])
+	}
+	// Only the suggested movies are fetched again in the naming language.
+	batch = slices.Clone(batch)
+	for i := range batch {
+		batch[i] = mr.localizeMovie(ctx, batch[i])
+	}
+	batch, mismatches := mr.rankMoviesByRuntime(ctx, suggestions.Movie, batch)
+	suggestions.SuggestedMovies = append(slices.Clip(suggestions.SuggestedMovies), batch...)
+	suggestions.Search.Rest = rest
+	if len(mismatches) > 0 {
+		runtimeMismatches := make(map[string]RuntimeMismatch, len(suggestions.RuntimeMismatches)+len(mismatches))
+		for id, mismatch := range suggestions.RuntimeMismatches {
+			runtimeMismatches[id] = mismatch
+		}
+		for id, mismatch := range mismatches {
+			runtimeMismatches[id] = mismatch
+		}
+		suggestions.RuntimeMismatches = runtimeMismatches
+	}
+	return suggestions
+}
+
+// searchTvShowPages is searchMoviePages for tv shows.
+func (mr *MediaRenamer) searchTvShowPages(ctx context.Context, query string, first mediadata.TvShowResults) []mediadata.TvShow {
+	tvShows := first.TvShows
+	pages := min(mr.searchDepth, pageCount(first.Totals, first.ResultsPerPage))
+	for page := 2; page <= pages; page++ {
+		next, err := mr.tvShowClient.SearchTvShow(ctx, query, 0, page)
+		if err != nil {
+			logger.FromContext(ctx).With("error", err).Warnf("Could not get page %d of '%s'", page, query)
+			break
+		}
+		tvShows = appendUnique(tvShows, next.TvShows, tvShowID)
+	}
+	return tvShows
+}
+
+// pageCount returns the number of pages of a search.
+func pageCount(totals, perPage int64) int {
+	if perPage <= 0 {
+		return 1
+	}
+	return int((totals + perPage - 1) / perPage)
+}
+
+// appendUnique appends the items of more not in list yet, as results can
+// shift between pages.
+func appendUnique[T any](list, more []T, id func(T) string) []T {
+	for _, item := range more {
+		if !slices.ContainsFunc(list, func(other T) bool { return id(other) == id(item) }) {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func movieID(movie mediadata.Movie) string {
+	return movie.ID
+}
+
+func tvShowID(tvShow mediadata.TvShow) string {
+	return tvShow.ID
+}
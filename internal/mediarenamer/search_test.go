@@ -0,0 +1,69 @@
+package mediarenamer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nouuu/gonamer/internal/mediadata"
+)
+
+func TestMoreMovies(t *testing.T) {
+	ctx := context.Background()
+	mr := &MediaRenamer{}
+	var candidates []mediadata.Movie
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		candidates = append(candidates, mediadata.Movie{ID: id})
+	}
+
+	suggestions := MovieSuggestions{Search: MovieSearch{Query: "Home", Page: 1, Pages: 1}}
+	suggestions = mr.suggestMovies(ctx, suggestions, candidates, 2)
+	wantIDs := func(want ...string) {
+		t.Helper()
+		if len(suggestions.SuggestedMovies) != len(want) {
+			t.Fatalf("suggested %v, want IDs %v", suggestions.SuggestedMovies, want)
+		}
+		for i, movie := range suggestions.SuggestedMovies {
+			if movie.ID != want[i] {
+				t.Fatalf("suggested %v, want IDs %v", suggestions.SuggestedMovies, want)
+			}
+		}
+	}
+	wantIDs("1", "2")
+
+	var err error
+	for _, want := range [][]string{{"1", "2", "3", "4"}, {"1", "2", "3", "4", "5"}} {
+		if !suggestions.Search.HasMore() {
+			t.Fatal("HasMore() = false, want true")
+		}
+		if suggestions, err = mr.MoreMovies(ctx, suggestions, 2); err != nil {
+			t.Fatalf("MoreMovies() error = %v", err)
+		}
+		wantIDs(want...)
+	}
+
+	if suggestions.Search.HasMore() {
+		t.Error("HasMore() = true after the last page, want false")
+	}
+	if _, err := mr.MoreMovies(ctx, suggestions, 2); !errors.Is(err, ErrNoMoreResults) {
+		t.Errorf("MoreMovies() error = %v, want ErrNoMoreResults", err)
+	}
+}
+
+func TestPageCount(t *testing.T) {
+	tests := []struct {
+		totals, perPage int64
+		want            int
+	}{
+		{0, 20, 0},
+		{1, 20, 1},
+		{20, 20, 1},
+		{21, 20, 2},
+		{5, 0, 1},
+	}
+	for _, tt := range tests {
+		if got := pageCount(tt.totals, tt.perPage); got != tt.want {
+			t.Errorf("pageCount(%d, %d) = %d, want %d", tt.totals, tt.perPage, got, tt.want)
+		}
+	}
+}
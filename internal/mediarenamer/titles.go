@@ -0,0 +1,42 @@
+package mediarenamer
+
+import (
+	"slices"
+
+	"github.com/nouuu/gonamer/internal/mediadata"
+	"github.com/nouuu/gonamer/pkg/normalize"
+)
+
+// preferTitleMatches moves first the candidates having name as title or
+// original title, keeping the search order otherwise. TMDB ranks its results
+// by popularity, so "Heat" can come after a more popular movie whose title
+// only contains it, and foreign files are often named after the original
+// title. Titles are compared by their normalize.Key, so "Rocky 2" matches
+// "Rocky II".
+func preferTitleMatches[T any](name string, candidates []T, titles func(T) []string) []T {
+	name = normalize.Key(name)
+	if name == "" {
+		return candidates
+	}
+	matches := func(candidate T) bool {
+		return slices.ContainsFunc(titles(candidate), func(title string) bool { return normalize.Key(title) == name })
+	}
+	preferred := make([]T, 0, len(candidates))
+	var others []T
+	for _, candidate := range candidates {
+		if matches(candidate) {
+			preferred = append(preferred, candidate)
+		} else {
+			others = append(others, candidate)
+		}
+	}
+	return append(preferred, others...)
+}
+
+func movieTitles(movie mediadata.Movie) []string {
+	return []string{movie.Title, movie.OriginalTitle}
+}
+
+func tvShowTitles(show SuggestedEpisode) []string {
+	return []string{show.TvShow.Title, show.TvShow.OriginalTitle}
+}
@@ -0,0 +1,32 @@
+package mediarenamer
+
+import (
+	"testing"
+
+	"github.com/nouuu/gonamer/internal/mediadata"
+)
+
+func TestPreferTitleMatches(t *testing.T) {
+	candidates := []mediadata.Movie{
+		{ID: "1", Title: "Heat Wave"},
+		{ID: "2", Title: "Heat"},
+		{ID: "3", Title: "Amélie", OriginalTitle: "Le Fabuleux Destin d'Amélie Poulain"},
+	}
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"Heat", []string{"2", "1", "3"}},
+		{"le fabuleux destin d amelie poulain", []string{"3", "1", "2"}},
+		{"Unknown", []string{"1", "2", "3"}},
+	}
+	for _, tt := range tests {
+		got := preferTitleMatches(tt.name, candidates, movieTitles)
+		for i, movie := range got {
+			if movie.ID != tt.want[i] {
+				t.Errorf("preferTitleMatches(%q) order = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
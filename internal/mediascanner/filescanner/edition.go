@@ -0,0 +1,86 @@
+package filescanner
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// editionTokens are checked in order, so that "Extended Director's Cut"
+	// is a director's cut.
+	editionTokens = []qualityToken{
+		token(`Director'?s[\s.\-_]?Cut`, "Director's Cut"),
+		token(`Final[\s.\-_]?Cut`, "Final Cut"),
+		token(`Ultimate[\s.\-_]?(?:Cut|Edition)`, "Ultimate Edition"),
+		token(`Special[\s.\-_]?Edition`, "Special Edition"),
+		token(`Criterion(?:[\s.\-_]?Collection)?`, "Criterion Collection"),
+		token(`Extended(?:[\s.\-_]?(?:Cut|Edition))?`, "Extended"),
+		token(`Theatrical(?:[\s.\-_]?(?:Cut|Edition|Release))?`, "Theatrical"),
+		token(`Unrated`, "Unrated"),
+		token(`Uncut`, "Uncut"),
+		token(`Remastered`, "Remastered"),
+		token(`IMAX(?:[\s.\-_]?Edition)?`, "IMAX"),
+	}
+	// discPartRegex matches the disc numbers of split releases anywhere in the
+	// name, e.g. "CD1" or "Disc 2".
+	discPartRegex = regexp.MustCompile(`(?i)(?:^|[\s._\-\[\(])(?:CD|Dis[ck])[\s._\-]?([1-9])(?:$|[\s._\-\]\)])`)
+	// namedPartRegex matches "Part 2" or "pt2", which are only part numbers
+	// after the year: before it they belong to the title, as in "Dune Part Two".
+	namedPartRegex = regexp.MustCompile(`(?i)(?:^|[\s._\-\[\(])(?:Part|Pt)[\s._\-]?([1-9])(?:$|[\s._\-\]\)])`)
+	// extraTokens map the release tokens of extras to the folders media
+	// servers look for.
+	extraTokens = []qualityToken{
+		token(`Trailers?|Teasers?`, "Trailers"),
+		token(`Behind[\s.\-_]?the[\s.\-_]?Scenes|Making[\s.\-_]?of`, "Behind The Scenes"),
+		token(`Deleted[\s.\-_]?Scenes?`, "Deleted Scenes"),
+		token(`Featurettes?`, "Featurettes"),
+		token(`Interviews?`, "Interviews"),
+	}
+	yearTokenRegex = regexp.MustCompile(`[\s._\-\(\[](?:19|20)\d{2}(?:$|[\s._\-\)\]])`)
+)
+
+// parseEdition extracts the edition and the part number of a movie file name
+// without extension, and returns the name without them so that they don't
+// end up in the search query. Like part numbers, editions only count after
+// the year: before it they belong to the title, as in "Uncut Gems" or
+// "The Final Cut".
+func parseEdition(nameWithoutExt string) (name, edition string, part int) {
+	name = nameWithoutExt
+	if year := yearTokenRegex.FindStringIndex(name); year != nil {
+		// The separator after the year starts the tokens that follow it.
+		title, afterYear := name[:year[1]-1], name[year[1]-1:]
+		for _, t := range editionTokens {
+			if t.regex.MatchString(afterYear) {
+				if edition == "" {
+					edition = t.value
+				}
+				afterYear = t.regex.ReplaceAllString(afterYear, " ")
+			}
+		}
+		name = title + afterYear
+	}
+
+	if m := discPartRegex.FindStringSubmatchIndex(name); m != nil {
+		part, _ = strconv.Atoi(name[m[2]:m[3]])
+		name = name[:m[0]] + " " + name[m[1]:]
+	} else if year := yearTokenRegex.FindStringIndex(name); year != nil {
+		afterYear := name[year[1]:]
+		if m := namedPartRegex.FindStringSubmatchIndex(afterYear); m != nil {
+			part, _ = strconv.Atoi(afterYear[m[2]:m[3]])
+			name = name[:year[1]] + afterYear[:m[0]] + " " + afterYear[m[1]:]
+		}
+	}
+	return strings.TrimSpace(name), edition, part
+}
+
+// parseExtra returns the extras folder of a trailer or bonus file. Like part
+// numbers, the tokens only count after the year, so that "The Interview" stays
+// a movie.
+func parseExtra(nameWithoutExt string) string {
+	year := yearTokenRegex.FindStringIndex(nameWithoutExt)
+	if year == nil {
+		return ""
+	}
+	return firstToken(nameWithoutExt[year[1]-1:], extraTokens)
+}
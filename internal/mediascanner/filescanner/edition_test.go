@@ -0,0 +1,49 @@
+package filescanner
+
+import "testing"
+
+func TestParseEdition(t *testing.T) {
+	tests := []struct {
+		name, wantName, wantEdition string
+		wantPart                    int
+	}{
+		{"Blade.Runner.1982.Directors.Cut.1080p.BluRay", "Blade.Runner.1982 1080p.BluRay", "Director's Cut", 0},
+		{"Blade Runner (1982) Director's Cut", "Blade Runner (1982)", "Director's Cut", 0},
+		{"The.Lord.of.the.Rings.2001.EXTENDED.720p", "The.Lord.of.the.Rings.2001 720p", "Extended", 0},
+		{"Interstellar.2014.IMAX.2160p", "Interstellar.2014 2160p", "IMAX", 0},
+		{"Kill.Bill.2003.CD2.DVDRip", "Kill.Bill.2003 DVDRip", "", 2},
+		{"Dune.Part.Two.2024.1080p", "Dune.Part.Two.2024.1080p", "", 0},
+		{"Harry.Potter.and.the.Deathly.Hallows.Part.2.2011", "Harry.Potter.and.the.Deathly.Hallows.Part.2.2011", "", 0},
+		{"Gone.with.the.Wind.1939.pt1.x264", "Gone.with.the.Wind.1939. x264", "", 1},
+		{"Amelie.2001.Theatrical.Cut", "Amelie.2001", "Theatrical", 0},
+		{"Uncut.Gems.2019.1080p", "Uncut.Gems.2019.1080p", "", 0},
+		{"Uncut.Gems.2019.Uncut", "Uncut.Gems.2019", "Uncut", 0},
+		{"The.Final.Cut.2004", "The.Final.Cut.2004", "", 0},
+		{"Unrated.2020", "Unrated.2020", "", 0},
+		{"Blade.Runner.Directors.Cut.1080p", "Blade.Runner.Directors.Cut.1080p", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, edition, part := parseEdition(tt.name)
+			if name != tt.wantName || edition != tt.wantEdition || part != tt.wantPart {
+				t.Errorf("parseEdition() = %q, %q, %d, want %q, %q, %d", name, edition, part, tt.wantName, tt.wantEdition, tt.wantPart)
+			}
+		})
+	}
+}
+
+func TestParseExtra(t *testing.T) {
+	tests := map[string]string{
+		"Dune.2021.Trailer.1080p":          "Trailers",
+		"Dune (2021)-trailer":              "Trailers",
+		"Alien.1979.Making.Of":             "Behind The Scenes",
+		"Alien.1979.Deleted.Scenes.DVDRip": "Deleted Scenes",
+		"The.Interview.2014.1080p":         "",
+		"Trailer.Park.Boys":                "",
+	}
+	for name, want := range tests {
+		if got := parseExtra(name); got != want {
+			t.Errorf("parseExtra(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package filescanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/nouuu/gonamer/internal/mediascanner"
+	"github.com/nouuu/gonamer/pkg/config"
+	"github.com/nouuu/gonamer/pkg/logger"
+)
+
+// maxNfoSize bounds the .nfo files read, which are small XML or text files.
+const maxNfoSize = 1 << 20
+
+var (
+	// idTagRegex matches the IDs media server layouts put in names:
+	// "{tmdb-603}", "[tmdbid-603]", "[tmdbid=603]", "{imdb-tt0133093}"...
+	idTagRegex = regexp.MustCompile(`(?i)[\[{]\s*(tmdb|imdb|tvdb)(?:id)?\s*[-=]\s*(tt\d{7,9}|\d+)\s*[\]}]`)
+	// imdbIDRegex matches a bare IMDb ID, as in "The.Matrix.1999.tt0133093.mkv"
+	// or an IMDb URL.
+	imdbIDRegex  = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(tt\d{7,9})(?:$|[^0-9])`)
+	tmdbURLRegex = regexp.MustCompile(`(?i)themoviedb\.org/(?:movie|tv)/(\d+)`)
+	tvdbURLRegex = regexp.MustCompile(`(?i)thetvdb\.com/(?:\?\S*?\bid=|dereferrer/series/|series/)(\d+)`)
+	// nfoUniqueIDRegex and nfoIDRegex match the Kodi .nfo elements, e.g.
+	// <uniqueid type="tmdb">603</uniqueid> or <imdbid>tt0133093</imdbid>.
+	nfoUniqueIDRegex = regexp.MustCompile(`(?is)<uniqueid[^>]*type="(tmdb|imdb|tvdb)"[^>]*>\s*([^<\s]+)\s*</uniqueid>`)
+	nfoIDRegex       = regexp.MustCompile(`(?is)<(tmdb|imdb|tvdb)_?id>\s*([^<\s]+)\s*</`)
+)
+
+// parseIDs returns the IDs found in text, a name or the content of a .nfo
+// file. The first ID of each database wins.
+func parseIDs(text string) (ids mediascanner.IDs) {
+	set := func(db, id string) {
+		switch strings.ToLower(db) {
+		case "tmdb":
+			ids.TMDB = first(ids.TMDB, id)
+		case "imdb":
+			if strings.HasPrefix(strings.ToLower(id), "tt") {
+				ids.IMDb = first(ids.IMDb, strings.ToLower(id))
+			}
+		case "tvdb":
+			ids.TVDB = first(ids.TVDB, id)
+		}
+	}
+	for _, regex := range []*regexp.Regexp{idTagRegex, nfoUniqueIDRegex, nfoIDRegex} {
+		for _, m := range regex.FindAllStringSubmatch(text, -1) {
+			set(m[1], m[2])
+		}
+	}
+	if m := tmdbURLRegex.FindStringSubmatch(text); m != nil {
+		set("tmdb", m[1])
+	}
+	if m := tvdbURLRegex.FindStringSubmatch(text); m != nil {
+		set("tvdb", m[1])
+	}
+	if m := imdbIDRegex.FindStringSubmatch(text); m != nil {
+		set("imdb", m[1])
+	}
+	return ids
+}
+
+// stripIDs removes the IDs from a name so that they don't end up in the
+// search query.
+func stripIDs(name string) string {
+	return imdbIDRegex.ReplaceAllString(idTagRegex.ReplaceAllString(name, " "), " ")
+}
+
+// lookupIDs returns the IDs of a file, read in order from its name, the .nfo
+// files next to it and its folder names. Episode IDs are those of the show, so
+// the .nfo of the episode itself is skipped for tvshow.nfo, and the season
+// folder is looked through.
+func lookupIDs(ctx context.Context, path string, episode bool, cfg *config.Config) mediascanner.IDs {
+	if cfg != nil && cfg.Scanner.DisableIDs {
+		return mediascanner.IDs{}
+	}
+	dir := filepath.Dir(path)
+	sources := []func() string{func() string { return filepath.Base(path) }}
+	if episode {
+		sources = append(sources,
+			func() string { return readNfo(ctx, filepath.Join(dir, "tvshow.nfo")) },
+			func() string { return readNfo(ctx, filepath.Join(filepath.Dir(dir), "tvshow.nfo")) },
+			func() string { return filepath.Base(dir) },
+			func() string { return filepath.Base(filepath.Dir(dir)) },
+		)
+	} else {
+		sources = append(sources,
+			func() string { return readNfo(ctx, strings.TrimSuffix(path, filepath.Ext(path))+".nfo") },
+			func() string { return readNfo(ctx, filepath.Join(dir, "movie.nfo")) },
+			func() string { return filepath.Base(dir) },
+		)
+	}
+
+	var ids mediascanner.IDs
+	for _, source := range sources {
+		found := parseIDs(source())
+		ids.TMDB = first(ids.TMDB, found.TMDB)
+		ids.IMDb = first(ids.IMDb, found.IMDb)
+		ids.TVDB = first(ids.TVDB, found.TVDB)
+	}
+	if !ids.IsZero() {
+		logger.FromContext(ctx).With("ids", ids).Debug("Found IDs of file")
+	}
+	return ids
+}
+
+// readNfo returns the content of a .nfo file, or "" when there is none.
+func readNfo(ctx context.Context, path string) string {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || info.Size() > maxNfoSize {
+		return ""
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		logger.FromContext(ctx).With("error", err).Debug("Could not read nfo file")
+		return ""
+	}
+	return string(content)
+}
+
+func first(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return fallback
+}
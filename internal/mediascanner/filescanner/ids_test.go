@@ -0,0 +1,61 @@
+package filescanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nouuu/gonamer/internal/mediascanner"
+)
+
+func TestParseIDs(t *testing.T) {
+	tests := []struct {
+		text string
+		want mediascanner.IDs
+	}{
+		{"The.Matrix.1999.1080p.tt0133093.mkv", mediascanner.IDs{IMDb: "tt0133093"}},
+		{"The Matrix (1999) {tmdb-603}", mediascanner.IDs{TMDB: "603"}},
+		{"Breaking Bad (2008) [tvdbid-81189]", mediascanner.IDs{TVDB: "81189"}},
+		{"The Matrix (1999) [tmdbid=603] {imdb-tt0133093}", mediascanner.IDs{TMDB: "603", IMDb: "tt0133093"}},
+		{"https://www.imdb.com/title/tt0133093/", mediascanner.IDs{IMDb: "tt0133093"}},
+		{"https://www.themoviedb.org/movie/603-the-matrix", mediascanner.IDs{TMDB: "603"}},
+		{"https://thetvdb.com/?tab=series&id=81189", mediascanner.IDs{TVDB: "81189"}},
+		{`<movie><uniqueid type="tmdb" default="true">603</uniqueid><uniqueid type="imdb">tt0133093</uniqueid></movie>`, mediascanner.IDs{TMDB: "603", IMDb: "tt0133093"}},
+		{"<tvshow><tvdbid>81189</tvdbid></tvshow>", mediascanner.IDs{TVDB: "81189"}},
+		{"Scott.Pilgrim.vs.the.World.2010.mkv", mediascanner.IDs{}},
+	}
+	for _, tt := range tests {
+		if got := parseIDs(tt.text); got != tt.want {
+			t.Errorf("parseIDs(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLookupIDs(t *testing.T) {
+	dir := t.TempDir()
+	movieDir := filepath.Join(dir, "The Matrix (1999) {tmdb-603}")
+	showDir := filepath.Join(dir, "Breaking Bad", "Season 01")
+	for _, d := range []string{movieDir, showDir} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(path, content string) {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(movieDir, "The Matrix (1999).nfo"), "https://www.imdb.com/title/tt0133093/")
+	write(filepath.Join(dir, "Breaking Bad", "tvshow.nfo"), `<tvshow><uniqueid type="tvdb">81189</uniqueid></tvshow>`)
+	// The .nfo of an episode holds its own IDs, not those of the show.
+	write(filepath.Join(showDir, "Breaking Bad S01E01.nfo"), `<episodedetails><uniqueid type="tmdb">62085</uniqueid></episodedetails>`)
+
+	ctx := context.Background()
+	if got, want := lookupIDs(ctx, filepath.Join(movieDir, "The Matrix (1999).mkv"), false, nil), (mediascanner.IDs{TMDB: "603", IMDb: "tt0133093"}); got != want {
+		t.Errorf("movie IDs = %+v, want %+v", got, want)
+	}
+	if got, want := lookupIDs(ctx, filepath.Join(showDir, "Breaking Bad S01E01.mkv"), true, nil), (mediascanner.IDs{TVDB: "81189"}); got != want {
+		t.Errorf("episode IDs = %+v, want %+v", got, want)
+	}
+}
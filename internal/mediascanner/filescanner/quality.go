@@ -0,0 +1,181 @@
+package filescanner
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/nouuu/gonamer/internal/mediascanner"
+	"github.com/nouuu/gonamer/internal/mediascanner/probe"
+	"github.com/nouuu/gonamer/pkg/config"
+	"github.com/nouuu/gonamer/pkg/logger"
+)
+
+// qualityToken maps the spellings found in release names to a normalized value.
+type qualityToken struct {
+	regex *regexp.Regexp
+	value string
+}
+
+// token builds a case-insensitive regex matching expr as a whole release token,
+// i.e. surrounded by separators or the ends of the name.
+func token(expr, value string) qualityToken {
+	return qualityToken{
+		regex: regexp.MustCompile(`(?i)(?:^|[\s._\-\[\(])(?:` + expr + `)(?:$|[\s._\-\]\)])`),
+		value: value,
+	}
+}
+
+var (
+	resolutionTokens = []qualityToken{
+		token(`2160p|4K|UHD`, "2160p"),
+		token(`1080[pi]`, "1080p"),
+		token(`720p`, "720p"),
+		token(`576[pi]`, "576p"),
+		token(`480[pi]`, "480p"),
+	}
+	sourceTokens = []qualityToken{
+		token(`(?:BD|BluRay)?[\s.\-]?Remux`, "Remux"),
+		token(`Blu-?Ray|BDRip|BRRip|BDMV`, "BluRay"),
+		token(`WEB-?Rip`, "WEBRip"),
+		token(`WEB-?DL|(?-i:WEB)`, "WEB-DL"),
+		token(`HDTV|PDTV`, "HDTV"),
+		token(`DVDRip|DVD-?R|DVD`, "DVDRip"),
+		token(`HDRip`, "HDRip"),
+		token(`HDCAM|TELESYNC|(?-i:CAM|TS)`, "CAM"),
+	}
+	videoCodecTokens = []qualityToken{
+		token(`x265|[Hh]\.?265|HEVC`, "x265"),
+		token(`x264|[Hh]\.?264|AVC`, "x264"),
+		token(`AV1`, "AV1"),
+		token(`VC-?1`, "VC-1"),
+		token(`XviD|DivX`, "XviD"),
+	}
+	dolbyVisionToken = token(`DoVi|Dolby[\s.]?Vision|(?-i:DV)`, "DV")
+	hdrTokens        = []qualityToken{
+		token(`HDR10\+|HDR10Plus`, "HDR10+"),
+		token(`HDR10`, "HDR10"),
+		token(`HDR`, "HDR"),
+		token(`HLG`, "HLG"),
+	}
+	audioCodecTokens = []qualityToken{
+		token(`TrueHD`, "TrueHD"),
+		token(`DTS-?HD[\s.\-]?MA|DTS-?MA`, "DTS-HD MA"),
+		token(`DTS-?X`, "DTS:X"),
+		token(`DTS-?HD`, "DTS-HD"),
+		token(`DTS`, "DTS"),
+		token(`DDP|DD\+|E-?AC-?3`, "EAC3"),
+		token(`AC-?3|(?-i:DD)`, "AC3"),
+		token(`AAC`, "AAC"),
+		token(`FLAC`, "FLAC"),
+		token(`Opus`, "Opus"),
+		token(`MP3`, "MP3"),
+	}
+	atmosToken  = token(`Atmos`, "Atmos")
+	repackToken = token(`REPACK|PROPER|RERIP`, "")
+
+	// Channels usually stick to the audio codec ("DDP5.1", "AAC2.0") or
+	// follow another token ("MA.5.1").
+	audioChannelsRegex = regexp.MustCompile(`(?i)(?:^|[\s_\-\[\(]|[a-z]\.|DDP|DD\+?|AAC|TrueHD|DTS|FLAC|Opus|AC3|Atmos)\.?([1-9])[\s.]([01])(?:$|[\s._\-\]\)])`)
+	// Codecs followed by their channel count ("DDP5", "AAC2") no longer match
+	// as whole tokens, so they are matched again with their digits removed.
+	stickyChannelsRegex = regexp.MustCompile(`(?i)(DDP|DD\+?|AAC|TrueHD|DTS|FLAC|Opus|AC3)[1-9][\s.][01]`)
+
+	trailingGroupRegex = regexp.MustCompile(`-([A-Za-z0-9]+)(?:\[[^\]]*\])?$`)
+	leadingGroupRegex  = regexp.MustCompile(`^\[([^\]]+)\]`)
+)
+
+// ParseQuality extracts the technical tokens of a file name without extension.
+func ParseQuality(nameWithoutExt string) (quality mediascanner.Quality) {
+	quality.Resolution = firstToken(nameWithoutExt, resolutionTokens)
+	quality.Source = firstToken(nameWithoutExt, sourceTokens)
+	quality.VideoCodec = firstToken(nameWithoutExt, videoCodecTokens)
+	quality.HDR = parseHDR(nameWithoutExt)
+
+	unstuck := stickyChannelsRegex.ReplaceAllString(nameWithoutExt, "$1.")
+	quality.AudioCodec = firstToken(unstuck, audioCodecTokens)
+	if atmosToken.regex.MatchString(nameWithoutExt) {
+		quality.AudioCodec = strings.TrimSpace(quality.AudioCodec + " Atmos")
+	}
+	if m := audioChannelsRegex.FindStringSubmatch(nameWithoutExt); m != nil {
+		quality.AudioChannels = m[1] + "." + m[2]
+	}
+
+	quality.Repack = repackToken.regex.MatchString(nameWithoutExt)
+	quality.ReleaseGroup = parseReleaseGroup(nameWithoutExt, quality)
+	return
+}
+
+func firstToken(name string, tokens []qualityToken) string {
+	for _, t := range tokens {
+		if t.regex.MatchString(name) {
+			return t.value
+		}
+	}
+	return ""
+}
+
+// parseHDR returns the HDR formats of the release; Dolby Vision releases often
+// carry an HDR10 fallback layer, which is kept as "DV HDR10".
+func parseHDR(name string) string {
+	var formats []string
+	if dolbyVisionToken.regex.MatchString(name) {
+		formats = append(formats, dolbyVisionToken.value)
+	}
+	if hdr := firstToken(name, hdrTokens); hdr != "" {
+		formats = append(formats, hdr)
+	}
+	return strings.Join(formats, " ")
+}
+
+// parseReleaseGroup returns the "-GROUP" suffix of scene names or the
+// "[Group]" prefix of fansub names. A suffix is only trusted when the name
+// has other release tokens, so that "Spider-Man" keeps its "Man".
+func parseReleaseGroup(name string, quality mediascanner.Quality) string {
+	if m := leadingGroupRegex.FindStringSubmatch(name); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	if quality.Resolution == "" && quality.Source == "" && quality.VideoCodec == "" {
+		return ""
+	}
+	m := trailingGroupRegex.FindStringSubmatch(name)
+	if m == nil {
+		return ""
+	}
+	// "WEB-DL" or "DTS-HD" at the end of a name are tokens, not groups.
+	suffix := name[:len(name)-len(m[0])]
+	for _, tokens := range [][]qualityToken{sourceTokens, audioCodecTokens} {
+		for _, t := range tokens {
+			if t.regex.MatchString(lastToken(suffix) + "-" + m[1]) {
+				return ""
+			}
+		}
+	}
+	return m[1]
+}
+
+func lastToken(name string) string {
+	if i := strings.LastIndexAny(name, " ._[("); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// probedExt are the containers the probe understands.
+var probedExt = []string{".mkv", ".mk3d", ".webm", ".mp4", ".m4v", ".mov", ".qt"}
+
+// probeFile reads the container headers of path. Failures only lose the
+// technical metadata, so they are logged and an empty MediaInfo is returned.
+func probeFile(ctx context.Context, path string, cfg *config.Config) mediascanner.MediaInfo {
+	if cfg == nil || cfg.Scanner.DisableProbe || !slices.Contains(probedExt, strings.ToLower(filepath.Ext(path))) {
+		return mediascanner.MediaInfo{}
+	}
+	info, err := probe.File(path)
+	if err != nil {
+		logger.FromContext(ctx).With("error", err).Debug("Could not probe media file")
+		return mediascanner.MediaInfo{}
+	}
+	return info
+}
@@ -0,0 +1,55 @@
+package filescanner
+
+import (
+	"testing"
+
+	"github.com/nouuu/gonamer/internal/mediascanner"
+)
+
+func TestParseQuality(t *testing.T) {
+	tests := []struct {
+		name string
+		want mediascanner.Quality
+	}{
+		{
+			name: "Dune.2021.2160p.WEB-DL.DV.HDR10.DDP5.1.Atmos.x265-FLUX",
+			want: mediascanner.Quality{Resolution: "2160p", Source: "WEB-DL", VideoCodec: "x265", HDR: "DV HDR10", AudioCodec: "EAC3 Atmos", AudioChannels: "5.1", ReleaseGroup: "FLUX"},
+		},
+		{
+			name: "The.Matrix.1999.1080p.BluRay.REMUX.AVC.TrueHD.7.1-FGT",
+			want: mediascanner.Quality{Resolution: "1080p", Source: "Remux", VideoCodec: "x264", AudioCodec: "TrueHD", AudioChannels: "7.1", ReleaseGroup: "FGT"},
+		},
+		{
+			name: "Peaky.Blinders.S01E01.REPACK.720p.HDTV.x264.AAC2.0",
+			want: mediascanner.Quality{Resolution: "720p", Source: "HDTV", VideoCodec: "x264", AudioCodec: "AAC", AudioChannels: "2.0", Repack: true},
+		},
+		{
+			name: "Movie.2020.1080p.WEBRip.DTS-HD.MA.5.1-GRP[rarbg]",
+			want: mediascanner.Quality{Resolution: "1080p", Source: "WEBRip", AudioCodec: "DTS-HD MA", AudioChannels: "5.1", ReleaseGroup: "GRP"},
+		},
+		{
+			name: "Movie.2020.1080p.WEB-DL",
+			want: mediascanner.Quality{Resolution: "1080p", Source: "WEB-DL"},
+		},
+		{
+			name: "[SubsPlease] Frieren - 01 (1080p)",
+			want: mediascanner.Quality{Resolution: "1080p", ReleaseGroup: "SubsPlease"},
+		},
+		{
+			name: "Charlotte's.Web.2006",
+			want: mediascanner.Quality{},
+		},
+		{
+			name: "Spider-Man",
+			want: mediascanner.Quality{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseQuality(tt.name); got != tt.want {
+				t.Errorf("ParseQuality() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -11,12 +11,12 @@ import (
 	"github.com/nouuu/gonamer/internal/mediascanner"
 	"github.com/nouuu/gonamer/pkg/config"
 	"github.com/nouuu/gonamer/pkg/logger"
+	"github.com/nouuu/gonamer/pkg/normalize"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
 var (
-	capitaliser = cases.Title(language.Turkish)
 	defaultDeleteRegex *regexp.Regexp
 	spaceRegex         = regexp.MustCompile(`[\._]`) // Bu basit olduğu için kalabilir.
 	extractDateRegex   = regexp.MustCompile(`^(.+?)\s?\(?(19\d{2}|20\d{2})\)?.*$`)
@@ -71,7 +71,7 @@ func init() {
 	joinedEpisodeKeywords := strings.Join(episodeKeywords, "|")
 
 	episodePatterns = []*regexp.Regexp{
-		regexp.MustCompile(fmt.Sprintf(`(?i)^(?P<name>.+?)[\. ]S(?P<season>\d{1,2})E(?P<episode>\d{1,3})`)),
+		regexp.MustCompile(fmt.Sprintf(`(?i)^(?P<name>.+?)[\. ]S(?P<season>\d{1,2})E(?P<episode>\d{1,3})(?:-?E(?P<end>\d{1,3}))?`)),
 		regexp.MustCompile(fmt.Sprintf(`(?i)^(?P<name>.+?)[\. ](?P<season>\d{1,2})x(?P<episode>\d{1,3})`)),
 		regexp.MustCompile(fmt.Sprintf(`(?i)^(?P<name>.+?)[\. ](%s)[\. ](?P<episode>\d{1,3})`, joinedEpisodeKeywords)),
 	}
@@ -85,7 +85,13 @@ func parseMovieFileName(ctx context.Context, fileName string, cfg *config.Config
 	movie.FullPath = fileName
 	movie.Extension = ext
 
-	movie.Name, movie.Year = sanitizeMovieName(ctx, nameWithoutExt, cfg)
+	var searchName string
+	searchName, movie.Edition, movie.Part = parseEdition(stripIDs(nameWithoutExt))
+	movie.Name, movie.Year = sanitizeMovieName(ctx, searchName, cfg)
+	movie.Extra = parseExtra(nameWithoutExt)
+	movie.Quality = ParseQuality(nameWithoutExt)
+	movie.Media = probeFile(ctx, fileName, cfg)
+	movie.IDs = lookupIDs(ctx, fileName, false, cfg)
 
 	return
 }
@@ -100,7 +106,10 @@ func parseEpisodeFileName(ctx context.Context, fileName string, cfg *config.Conf
 	episode.Extension = ext
 
 	var ignore bool
-	episode.Name, episode.Season, episode.Episode, ignore = sanitizeEpisodeName(ctx, nameWithoutExt, cfg)
+	episode.Name, episode.Season, episode.Episode, episode.EpisodeEnd, ignore = sanitizeEpisodeName(ctx, stripIDs(nameWithoutExt), cfg)
+	episode.Quality = ParseQuality(nameWithoutExt)
+	episode.Media = probeFile(ctx, fileName, cfg)
+	episode.IDs = lookupIDs(ctx, fileName, true, cfg)
 
 	if ignore {
 		episode = mediascanner.Episode{
@@ -125,14 +134,16 @@ func sanitizeMovieName(ctx context.Context, nameWithoutExt string, cfg *config.C
 	return
 }
 
-func sanitizeEpisodeName(ctx context.Context, nameWithoutExt string, cfg *config.Config) (name string, season int, episode int, ignore bool) {
+func sanitizeEpisodeName(ctx context.Context, nameWithoutExt string, cfg *config.Config) (name string, season, episode, episodeEnd int, ignore bool) {
 	cleanedName := sanitizeString(nameWithoutExt, cfg)
 
 	return parseEpisodeName(ctx, cleanedName, cfg.Scanner.ExcludeUnparsed)
 }
 
 
-func parseEpisodeName(ctx context.Context, name string, excludeUnparsed bool) (string, int, int, bool) {
+// parseEpisodeName returns the show name, season, episode and, for files
+// holding several episodes ("S01E01-E02"), the last episode number.
+func parseEpisodeName(ctx context.Context, name string, excludeUnparsed bool) (string, int, int, int, bool) {
 	log := logger.FromContext(ctx)
 	for _, pattern := range episodePatterns {
 		matches := pattern.FindStringSubmatch(name)
@@ -150,18 +161,23 @@ func parseEpisodeName(ctx context.Context, name string, excludeUnparsed bool) (s
 			}
 			season, _ := strconv.Atoi(seasonStr)
 			episode, _ := strconv.Atoi(result["episode"])
-			return showName, season, episode, false
+			episodeEnd, _ := strconv.Atoi(result["end"])
+			if episodeEnd <= episode {
+				episodeEnd = 0
+			}
+			return showName, season, episode, episodeEnd, false
 		}
 	}
 	log.With("name", name).Debug("No episode pattern matched.")
 	if excludeUnparsed {
-		return name, 0, 0, true
+		return name, 0, 0, 0, true
 	}
-	return name, 1, 1, false
+	return name, 1, 1, 0, false
 }
 
 
 func sanitizeString(str string, cfg *config.Config) string {
+	str = normalize.NFC(str)
 	str = spaceRegex.ReplaceAllString(str, " ")
 
 	str = defaultDeleteRegex.ReplaceAllString(str, "")
@@ -177,6 +193,23 @@ func sanitizeString(str string, cfg *config.Config) string {
 	}
 	space := regexp.MustCompile(`\s+`)
 	str = space.ReplaceAllString(str, " ")
-	return capitaliser.String(strings.TrimSpace(str))
+	return titleCase(strings.TrimSpace(str), cfg)
+}
+
+// titleCase capitalises the words of a parsed name in the casing locale, which
+// decides letters like the Turkish dotted I.
+func titleCase(str string, cfg *config.Config) string {
+	casing := config.DefaultCasing
+	if cfg != nil && cfg.Scanner.Casing != "" {
+		casing = cfg.Scanner.Casing
+	}
+	if casing == config.CasingNone {
+		return str
+	}
+	tag, err := language.Parse(casing)
+	if err != nil {
+		tag = language.Und
+	}
+	return cases.Title(tag).String(str)
 }
 
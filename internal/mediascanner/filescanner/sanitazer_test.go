@@ -0,0 +1,50 @@
+package filescanner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nouuu/gonamer/pkg/config"
+)
+
+func TestParseEpisodeName(t *testing.T) {
+	tests := []struct {
+		name                    string
+		wantShow                string
+		wantSeason, wantEpisode int
+		wantEpisodeEnd          int
+	}{
+		{"Breaking Bad S01E01", "Breaking Bad", 1, 1, 0},
+		{"Breaking Bad S01E01-E02", "Breaking Bad", 1, 1, 2},
+		{"Breaking Bad S01E01E02 720p", "Breaking Bad", 1, 1, 2},
+		{"Breaking Bad 2x05", "Breaking Bad", 2, 5, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			show, season, episode, end, _ := parseEpisodeName(context.Background(), tt.name, false)
+			if show != tt.wantShow || season != tt.wantSeason || episode != tt.wantEpisode || end != tt.wantEpisodeEnd {
+				t.Errorf("parseEpisodeName() = %q, %d, %d, %d, want %q, %d, %d, %d",
+					show, season, episode, end, tt.wantShow, tt.wantSeason, tt.wantEpisode, tt.wantEpisodeEnd)
+			}
+		})
+	}
+}
+
+func TestSanitizeStringCasing(t *testing.T) {
+	tests := []struct {
+		casing string
+		want   string
+	}{
+		{"", "İnception Amélie"},
+		{"tr", "İnception Amélie"},
+		{"en", "Inception Amélie"},
+		{config.CasingNone, "inception Amélie"},
+	}
+	for _, tt := range tests {
+		cfg := &config.Config{Scanner: config.ScannerConfig{Casing: tt.casing}}
+		// The name is NFD-encoded, as copied from macOS.
+		if got := sanitizeString("inception.Ame\u0301lie", cfg); got != tt.want {
+			t.Errorf("casing %q: sanitizeString() = %q, want %q", tt.casing, got, tt.want)
+		}
+	}
+}
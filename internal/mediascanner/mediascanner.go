@@ -2,6 +2,10 @@ package mediascanner
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/nouuu/gonamer/pkg/config"
 )
 type ScanMoviesOptions struct {
@@ -19,7 +23,17 @@ type Movie struct {
 	Name             string
 	Year             int
 	Extension        string
-	Quality          string
+	// Edition is the cut of the release, e.g. "Director's Cut" or "IMAX".
+	Edition string
+	// Part is the number of a movie split over several files ("CD1",
+	// "Part 2"), 0 when it isn't split.
+	Part int
+	// Extra is the extras folder of a trailer, featurette... e.g. "Trailers",
+	// empty for the movie itself.
+	Extra   string
+	Quality Quality
+	Media   MediaInfo
+	IDs     IDs
 }
 
 type Episode struct {
@@ -28,8 +42,160 @@ type Episode struct {
 	Name             string
 	Season           int
 	Episode          int
-	Extension        string
-	Quality          string
+	// EpisodeEnd is the last episode of a file holding several episodes, as
+	// in "S01E01-E02", 0 otherwise.
+	EpisodeEnd int
+	Extension  string
+	Quality    Quality
+	Media      MediaInfo
+	// IDs are those of the show.
+	IDs IDs
+}
+
+// IDs are the database IDs found in a file name, its folders or a .nfo file
+// next to it. They identify the media without searching.
+type IDs struct {
+	TMDB string
+	IMDb string
+	TVDB string
+}
+
+func (ids IDs) IsZero() bool {
+	return ids == IDs{}
+}
+
+// Quality holds the technical tokens of a release name, such as
+// "Dune.2021.2160p.WEB-DL.DV.HDR10.DDP5.1.Atmos.x265-FLUX".
+type Quality struct {
+	Resolution    string // 2160p, 1080p, 720p...
+	Source        string // BluRay, Remux, WEB-DL, WEBRip, HDTV, DVDRip...
+	VideoCodec    string // x264, x265, AV1...
+	HDR           string // DV, HDR10+, HDR10, HDR, HLG, possibly combined like "DV HDR10"
+	AudioCodec    string // TrueHD Atmos, DTS-HD MA, EAC3, AC3, AAC...
+	AudioChannels string // 7.1, 5.1, 2.0
+	ReleaseGroup  string
+	Repack        bool // REPACK or PROPER release
+}
+
+// Audio returns the audio codec followed by its channel layout, e.g. "EAC3 5.1".
+func (q Quality) Audio() string {
+	return strings.TrimSpace(q.AudioCodec + " " + q.AudioChannels)
+}
+
+func (q Quality) String() string {
+	parts := make([]string, 0, 5)
+	for _, p := range []string{q.Resolution, q.Source, q.HDR, q.VideoCodec, q.Audio()} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// MediaInfo is the technical metadata read from the container headers. It is
+// zero when the file could not be probed.
+type MediaInfo struct {
+	Duration    time.Duration
+	Width       int
+	Height      int
+	VideoCodec  string // x264, x265, AV1, VP9...
+	AudioTracks []AudioTrack
+	Subtitles   []SubtitleTrack
+}
+
+type AudioTrack struct {
+	Codec    string // AAC, AC3, EAC3, DTS, TrueHD...
+	Language string // ISO 639 code as stored in the container, e.g. "eng" or "fre"
+	Channels int
+	Default  bool
+}
+
+type SubtitleTrack struct {
+	Codec    string // SRT, ASS, PGS, VobSub...
+	Language string
+	Forced   bool
+}
+
+func (m MediaInfo) IsZero() bool {
+	return m.Duration == 0 && m.Width == 0 && m.Height == 0 && m.VideoCodec == "" && len(m.AudioTracks) == 0 && len(m.Subtitles) == 0
+}
+
+// Runtime returns the duration rounded to minutes.
+func (m MediaInfo) Runtime() int {
+	return int(m.Duration.Round(time.Minute) / time.Minute)
+}
+
+// Resolution returns the usual name of the video height, such as "1080p".
+// The width is checked too so that cropped scope releases (1920x800) still
+// count as 1080p.
+func (m MediaInfo) Resolution() string {
+	switch {
+	case m.Width == 0 && m.Height == 0:
+		return ""
+	case m.Width >= 3200 || m.Height >= 1800:
+		return "2160p"
+	case m.Width >= 1800 || m.Height >= 1000:
+		return "1080p"
+	case m.Width >= 1200 || m.Height >= 700:
+		return "720p"
+	case m.Height >= 560:
+		return "576p"
+	default:
+		return "480p"
+	}
+}
+
+// MainAudio returns the default audio track, or the first one.
+func (m MediaInfo) MainAudio() (AudioTrack, bool) {
+	for _, track := range m.AudioTracks {
+		if track.Default {
+			return track, true
+		}
+	}
+	if len(m.AudioTracks) > 0 {
+		return m.AudioTracks[0], true
+	}
+	return AudioTrack{}, false
+}
+
+// Layout returns the usual channel layout name, e.g. 6 channels → "5.1".
+func (t AudioTrack) Layout() string {
+	switch {
+	case t.Channels <= 0:
+		return ""
+	case t.Channels <= 2:
+		return fmt.Sprintf("%d.0", t.Channels)
+	default:
+		return fmt.Sprintf("%d.1", t.Channels-1)
+	}
+}
+
+func (m MediaInfo) AudioLanguages() []string {
+	languages := make([]string, 0, len(m.AudioTracks))
+	for _, track := range m.AudioTracks {
+		languages = appendLanguage(languages, track.Language)
+	}
+	return languages
+}
+
+func (m MediaInfo) SubtitleLanguages() []string {
+	languages := make([]string, 0, len(m.Subtitles))
+	for _, track := range m.Subtitles {
+		languages = appendLanguage(languages, track.Language)
+	}
+	return languages
+}
+
+func appendLanguage(languages []string, language string) []string {
+	if language == "" || language == "und" {
+		return languages
+	}
+	for _, l := range languages {
+		if l == language {
+			return languages
+		}
+	}
+	return append(languages, language)
 }
 
 type MediaScanner interface {
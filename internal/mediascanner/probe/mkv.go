@@ -0,0 +1,421 @@
+package probe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/nouuu/gonamer/internal/mediascanner"
+)
+
+// Matroska element IDs, see https://www.matroska.org/technical/elements.html
+const (
+	idEBML          = 0x1A45DFA3
+	idSegment       = 0x18538067
+	idSeekHead      = 0x114D9B74
+	idSeek          = 0x4DBB
+	idSeekID        = 0x53AB
+	idSeekPosition  = 0x53AC
+	idInfo          = 0x1549A966
+	idTimecodeScale = 0x2AD7B1
+	idDuration      = 0x4489
+	idTracks        = 0x1654AE6B
+	idTrackEntry    = 0xAE
+	idTrackType     = 0x83
+	idCodecID       = 0x86
+	idLanguage      = 0x22B59C
+	idLanguageIETF  = 0x22B59D
+	idFlagDefault   = 0x88
+	idFlagForced    = 0x55AA
+	idVideo         = 0xE0
+	idPixelWidth    = 0xB0
+	idPixelHeight   = 0xBA
+	idAudio         = 0xE1
+	idChannels      = 0x9F
+	idCluster       = 0x1F43B675
+)
+
+const (
+	trackTypeVideo    = 1
+	trackTypeAudio    = 2
+	trackTypeSubtitle = 0x11
+)
+
+const unknownSize = -1
+
+type element struct {
+	id         uint64
+	size       int64
+	dataOffset int64
+}
+
+func (e element) end(limit int64) int64 {
+	if e.size == unknownSize || e.dataOffset+e.size > limit {
+		return limit
+	}
+	return e.dataOffset + e.size
+}
+
+func probeMatroska(r io.ReadSeeker) (mediascanner.MediaInfo, error) {
+	var info mediascanner.MediaInfo
+
+	fileEnd, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return info, err
+	}
+
+	header, err := readElement(r, 0)
+	if err != nil || header.id != idEBML {
+		return info, ErrUnsupported
+	}
+
+	segment, err := findElement(r, header.end(fileEnd), fileEnd, idSegment)
+	if err != nil {
+		return info, err
+	}
+	segmentEnd := segment.end(fileEnd)
+
+	var foundInfo, foundTracks bool
+	seeks := make(map[uint64]int64)
+	parse := func(el element) error {
+		data, err := readAt(r, el.dataOffset, el.end(segmentEnd)-el.dataOffset)
+		if err != nil {
+			return err
+		}
+		switch el.id {
+		case idSeekHead:
+			return parseSeekHead(data, seeks)
+		case idInfo:
+			foundInfo = true
+			return parseSegmentInfo(data, &info)
+		case idTracks:
+			foundTracks = true
+			return parseTracks(data, &info)
+		}
+		return nil
+	}
+
+	// Info and Tracks usually come before the first cluster; when they don't,
+	// the seek head tells where they are.
+	for pos := segment.dataOffset; pos < segmentEnd && !(foundInfo && foundTracks); {
+		el, err := readElement(r, pos)
+		if err != nil {
+			break
+		}
+		if el.id == idCluster {
+			break
+		}
+		if el.id == idSeekHead || el.id == idInfo || el.id == idTracks {
+			if err := parse(el); err != nil {
+				return info, err
+			}
+		}
+		if el.size == unknownSize {
+			break
+		}
+		pos = el.dataOffset + el.size
+	}
+
+	for _, id := range []uint64{idInfo, idTracks} {
+		if (id == idInfo && foundInfo) || (id == idTracks && foundTracks) {
+			continue
+		}
+		position, ok := seeks[id]
+		if !ok {
+			continue
+		}
+		el, err := readElement(r, segment.dataOffset+position)
+		if err != nil || el.id != id {
+			continue
+		}
+		if err := parse(el); err != nil {
+			return info, err
+		}
+	}
+
+	if !foundInfo && !foundTracks {
+		return info, errors.New("no segment info nor tracks found")
+	}
+	return info, nil
+}
+
+// findElement returns the first top-level element with id between from and to.
+func findElement(r io.ReadSeeker, from, to int64, id uint64) (element, error) {
+	for pos := from; pos < to; {
+		el, err := readElement(r, pos)
+		if err != nil {
+			return element{}, err
+		}
+		if el.id == id {
+			return el, nil
+		}
+		if el.size == unknownSize {
+			break
+		}
+		pos = el.dataOffset + el.size
+	}
+	return element{}, fmt.Errorf("element %x not found", id)
+}
+
+// readElement reads the ID and size of the element starting at offset.
+func readElement(r io.ReadSeeker, offset int64) (element, error) {
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return element{}, err
+	}
+	buf := make([]byte, 12)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return element{}, err
+	}
+	id, size, headerLen, err := parseElementHeader(buf[:n])
+	if err != nil {
+		return element{}, err
+	}
+	return element{id: id, size: size, dataOffset: offset + int64(headerLen)}, nil
+}
+
+func parseElementHeader(buf []byte) (id uint64, size int64, n int, err error) {
+	id, idLen, err := readVint(buf, true)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	rawSize, sizeLen, err := readVint(buf[idLen:], false)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	size = int64(rawSize)
+	if rawSize == (1<<(7*uint(sizeLen)))-1 {
+		size = unknownSize
+	}
+	return id, size, idLen + sizeLen, nil
+}
+
+// readVint decodes an EBML variable-length integer. IDs keep their length
+// marker bit, sizes don't.
+func readVint(buf []byte, keepMarker bool) (uint64, int, error) {
+	if len(buf) == 0 {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	length := 1
+	for mask := byte(0x80); length <= 8 && buf[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > 8 || (keepMarker && length > 4) {
+		return 0, 0, errors.New("invalid EBML variable-length integer")
+	}
+	if len(buf) < length {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+
+	value := uint64(buf[0])
+	if !keepMarker {
+		value &= uint64(0xFF >> length)
+	}
+	for _, b := range buf[1:length] {
+		value = value<<8 | uint64(b)
+	}
+	return value, length, nil
+}
+
+// eachChild calls fn for every element of an in-memory master element.
+func eachChild(data []byte, fn func(id uint64, payload []byte) error) error {
+	for len(data) > 0 {
+		id, size, n, err := parseElementHeader(data)
+		if err != nil {
+			return err
+		}
+		data = data[n:]
+		if size == unknownSize || size > int64(len(data)) {
+			size = int64(len(data))
+		}
+		if err := fn(id, data[:size]); err != nil {
+			return err
+		}
+		data = data[size:]
+	}
+	return nil
+}
+
+func parseSeekHead(data []byte, seeks map[uint64]int64) error {
+	return eachChild(data, func(id uint64, payload []byte) error {
+		if id != idSeek {
+			return nil
+		}
+		var seekID uint64
+		var position int64
+		err := eachChild(payload, func(id uint64, payload []byte) error {
+			switch id {
+			case idSeekID:
+				seekID = readUint(payload)
+			case idSeekPosition:
+				position = int64(readUint(payload))
+			}
+			return nil
+		})
+		if seekID != 0 {
+			seeks[seekID] = position
+		}
+		return err
+	})
+}
+
+func parseSegmentInfo(data []byte, info *mediascanner.MediaInfo) error {
+	scale := uint64(1000000)
+	var duration float64
+	err := eachChild(data, func(id uint64, payload []byte) error {
+		switch id {
+		case idTimecodeScale:
+			scale = readUint(payload)
+		case idDuration:
+			duration = readFloat(payload)
+		}
+		return nil
+	})
+	info.Duration = time.Duration(duration * float64(scale))
+	return err
+}
+
+func parseTracks(data []byte, info *mediascanner.MediaInfo) error {
+	return eachChild(data, func(id uint64, payload []byte) error {
+		if id != idTrackEntry {
+			return nil
+		}
+
+		var trackType uint64
+		var codecID, language, languageIETF string
+		isDefault, forced := true, false
+		var width, height, channels int
+		err := eachChild(payload, func(id uint64, payload []byte) error {
+			switch id {
+			case idTrackType:
+				trackType = readUint(payload)
+			case idCodecID:
+				codecID = readString(payload)
+			case idLanguage:
+				language = readString(payload)
+			case idLanguageIETF:
+				languageIETF = readString(payload)
+			case idFlagDefault:
+				isDefault = readUint(payload) == 1
+			case idFlagForced:
+				forced = readUint(payload) == 1
+			case idVideo:
+				return eachChild(payload, func(id uint64, payload []byte) error {
+					switch id {
+					case idPixelWidth:
+						width = int(readUint(payload))
+					case idPixelHeight:
+						height = int(readUint(payload))
+					}
+					return nil
+				})
+			case idAudio:
+				return eachChild(payload, func(id uint64, payload []byte) error {
+					if id == idChannels {
+						channels = int(readUint(payload))
+					}
+					return nil
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// Matroska defaults the language to English when it isn't set.
+		lang := "eng"
+		if languageIETF != "" {
+			lang = languageIETF
+		} else if language != "" {
+			lang = language
+		}
+
+		switch trackType {
+		case trackTypeVideo:
+			if info.VideoCodec == "" {
+				info.VideoCodec = matroskaCodec(codecID)
+				info.Width, info.Height = width, height
+			}
+		case trackTypeAudio:
+			info.AudioTracks = append(info.AudioTracks, mediascanner.AudioTrack{
+				Codec:    matroskaCodec(codecID),
+				Language: lang,
+				Channels: channels,
+				Default:  isDefault,
+			})
+		case trackTypeSubtitle:
+			info.Subtitles = append(info.Subtitles, mediascanner.SubtitleTrack{
+				Codec:    matroskaCodec(codecID),
+				Language: lang,
+				Forced:   forced,
+			})
+		}
+		return nil
+	})
+}
+
+var matroskaCodecs = []struct {
+	prefix string
+	name   string
+}{
+	{"V_MPEG4/ISO/AVC", "x264"},
+	{"V_MPEGH/ISO/HEVC", "x265"},
+	{"V_AV1", "AV1"},
+	{"V_VP9", "VP9"},
+	{"V_VP8", "VP8"},
+	{"V_MPEG4/ISO", "XviD"},
+	{"V_MS/VFW/FOURCC", "XviD"},
+	{"V_MPEG2", "MPEG-2"},
+	{"A_AAC", "AAC"},
+	{"A_EAC3", "EAC3"},
+	{"A_AC3", "AC3"},
+	{"A_DTS", "DTS"},
+	{"A_TRUEHD", "TrueHD"},
+	{"A_FLAC", "FLAC"},
+	{"A_OPUS", "Opus"},
+	{"A_VORBIS", "Vorbis"},
+	{"A_MPEG/L3", "MP3"},
+	{"A_PCM", "PCM"},
+	{"S_TEXT/UTF8", "SRT"},
+	{"S_TEXT/ASS", "ASS"},
+	{"S_TEXT/SSA", "ASS"},
+	{"S_TEXT/WEBVTT", "WebVTT"},
+	{"S_HDMV/PGS", "PGS"},
+	{"S_VOBSUB", "VobSub"},
+}
+
+func matroskaCodec(codecID string) string {
+	for _, c := range matroskaCodecs {
+		if strings.HasPrefix(codecID, c.prefix) {
+			return c.name
+		}
+	}
+	return codecID
+}
+
+func readUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+func readFloat(b []byte) float64 {
+	switch len(b) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func readString(b []byte) string {
+	return strings.TrimRight(string(b), "\x00")
+}
@@ -0,0 +1,281 @@
+package probe
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/nouuu/gonamer/internal/mediascanner"
+)
+
+type box struct {
+	typ        string
+	dataOffset int64
+	end        int64
+}
+
+// isMP4Box reports whether typ is a box that can start an ISO/QuickTime file.
+func isMP4Box(typ string) bool {
+	switch typ {
+	case "ftyp", "moov", "mdat", "free", "skip", "wide", "pnot":
+		return true
+	}
+	return false
+}
+
+func probeMP4(r io.ReadSeeker) (mediascanner.MediaInfo, error) {
+	var info mediascanner.MediaInfo
+
+	fileEnd, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return info, err
+	}
+
+	// moov is often written after mdat, so walk the top-level boxes by seeking
+	// over them rather than reading.
+	for pos := int64(0); pos < fileEnd; {
+		b, err := readBox(r, pos, fileEnd)
+		if err != nil {
+			return info, err
+		}
+		if b.typ == "moov" {
+			data, err := readAt(r, b.dataOffset, b.end-b.dataOffset)
+			if err != nil {
+				return info, err
+			}
+			return info, parseMoov(data, &info)
+		}
+		pos = b.end
+	}
+	return info, errors.New("no moov box found")
+}
+
+func readBox(r io.ReadSeeker, offset, limit int64) (box, error) {
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return box{}, err
+	}
+	header := make([]byte, 16)
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return box{}, err
+	}
+	b, err := parseBoxHeader(header[:n], offset, limit)
+	if err != nil {
+		return box{}, err
+	}
+	return b, nil
+}
+
+func parseBoxHeader(header []byte, offset, limit int64) (box, error) {
+	if len(header) < 8 {
+		return box{}, io.ErrUnexpectedEOF
+	}
+	size := int64(binary.BigEndian.Uint32(header))
+	b := box{typ: string(header[4:8]), dataOffset: offset + 8}
+	switch size {
+	case 0:
+		b.end = limit
+	case 1:
+		if len(header) < 16 {
+			return box{}, io.ErrUnexpectedEOF
+		}
+		size = int64(binary.BigEndian.Uint64(header[8:16]))
+		b.dataOffset = offset + 16
+		b.end = offset + size
+	default:
+		b.end = offset + size
+	}
+	if b.end < b.dataOffset || b.end > limit {
+		b.end = limit
+	}
+	return b, nil
+}
+
+// eachBox calls fn for every box of an in-memory container box.
+func eachBox(data []byte, fn func(typ string, payload []byte) error) error {
+	for pos := int64(0); pos < int64(len(data)); {
+		b, err := parseBoxHeader(data[pos:], pos, int64(len(data)))
+		if err != nil {
+			return err
+		}
+		if err := fn(b.typ, data[b.dataOffset:b.end]); err != nil {
+			return err
+		}
+		if b.end <= pos {
+			break
+		}
+		pos = b.end
+	}
+	return nil
+}
+
+func parseMoov(data []byte, info *mediascanner.MediaInfo) error {
+	return eachBox(data, func(typ string, payload []byte) error {
+		switch typ {
+		case "mvhd":
+			timescale, duration := parseTimes(payload)
+			if timescale > 0 {
+				info.Duration = time.Duration(float64(duration) / float64(timescale) * float64(time.Second))
+			}
+		case "trak":
+			return parseTrak(payload, info)
+		}
+		return nil
+	})
+}
+
+// parseTimes reads the timescale and duration of a mvhd or mdhd box.
+func parseTimes(payload []byte) (timescale uint32, duration uint64) {
+	if len(payload) < 4 {
+		return 0, 0
+	}
+	if payload[0] == 1 {
+		if len(payload) < 32 {
+			return 0, 0
+		}
+		return binary.BigEndian.Uint32(payload[20:24]), binary.BigEndian.Uint64(payload[24:32])
+	}
+	if len(payload) < 20 {
+		return 0, 0
+	}
+	return binary.BigEndian.Uint32(payload[12:16]), uint64(binary.BigEndian.Uint32(payload[16:20]))
+}
+
+type mp4Track struct {
+	handler  string
+	language string
+	format   string
+	width    int
+	height   int
+	channels int
+	enabled  bool
+}
+
+func parseTrak(data []byte, info *mediascanner.MediaInfo) error {
+	var track mp4Track
+	var walk func(data []byte) error
+	walk = func(data []byte) error {
+		return eachBox(data, func(typ string, payload []byte) error {
+			switch typ {
+			case "mdia", "minf", "stbl":
+				return walk(payload)
+			case "tkhd":
+				track.enabled = len(payload) >= 4 && payload[3]&1 == 1
+			case "mdhd":
+				track.language = mdhdLanguage(payload)
+			case "hdlr":
+				if len(payload) >= 12 {
+					track.handler = string(payload[8:12])
+				}
+			case "stsd":
+				parseSampleDescription(payload, &track)
+			}
+			return nil
+		})
+	}
+	if err := walk(data); err != nil {
+		return err
+	}
+
+	switch track.handler {
+	case "vide":
+		if info.VideoCodec == "" {
+			info.VideoCodec = mp4Codec(track.format)
+			info.Width, info.Height = track.width, track.height
+		}
+	case "soun":
+		info.AudioTracks = append(info.AudioTracks, mediascanner.AudioTrack{
+			Codec:    mp4Codec(track.format),
+			Language: track.language,
+			Channels: track.channels,
+			Default:  track.enabled,
+		})
+	case "subt", "text", "sbtl", "clcp":
+		info.Subtitles = append(info.Subtitles, mediascanner.SubtitleTrack{
+			Codec:    mp4Codec(track.format),
+			Language: track.language,
+		})
+	}
+	return nil
+}
+
+// mdhdLanguage decodes the packed ISO 639-2/T code of a mdhd box.
+func mdhdLanguage(payload []byte) string {
+	offset := 20
+	if len(payload) > 0 && payload[0] == 1 {
+		offset = 32
+	}
+	if len(payload) < offset+2 {
+		return ""
+	}
+	packed := binary.BigEndian.Uint16(payload[offset : offset+2])
+	if packed == 0 || packed == 0x7FFF {
+		return ""
+	}
+	lang := []byte{
+		byte(packed>>10&0x1F) + 0x60,
+		byte(packed>>5&0x1F) + 0x60,
+		byte(packed&0x1F) + 0x60,
+	}
+	return string(lang)
+}
+
+// parseSampleDescription reads the format of the first sample entry, with the
+// picture size of video entries and the channel count of audio ones.
+func parseSampleDescription(payload []byte, track *mp4Track) {
+	// version/flags (4) and entry count (4), then the first sample entry.
+	if len(payload) < 24 {
+		return
+	}
+	entry := payload[8:]
+	track.format = string(entry[4:8])
+	// size (4), format (4), reserved (6), data reference index (2)
+	fields := entry[16:]
+	switch track.handler {
+	case "vide":
+		// pre-defined and reserved (16), then width and height.
+		if len(fields) >= 20 {
+			track.width = int(binary.BigEndian.Uint16(fields[16:18]))
+			track.height = int(binary.BigEndian.Uint16(fields[18:20]))
+		}
+	case "soun":
+		// reserved (8), then channel count.
+		if len(fields) >= 10 {
+			track.channels = int(binary.BigEndian.Uint16(fields[8:10]))
+		}
+	}
+}
+
+var mp4Codecs = map[string]string{
+	"avc1": "x264",
+	"avc3": "x264",
+	"hvc1": "x265",
+	"hev1": "x265",
+	"dvh1": "x265",
+	"dvhe": "x265",
+	"av01": "AV1",
+	"vp09": "VP9",
+	"mp4v": "XviD",
+	"mp4a": "AAC",
+	"ac-3": "AC3",
+	"ec-3": "EAC3",
+	"dtsc": "DTS",
+	"dtsh": "DTS",
+	"dtsl": "DTS",
+	"mlpa": "TrueHD",
+	"fLaC": "FLAC",
+	"Opus": "Opus",
+	".mp3": "MP3",
+	"tx3g": "TX3G",
+	"wvtt": "WebVTT",
+	"stpp": "TTML",
+	"c608": "CEA-608",
+}
+
+func mp4Codec(format string) string {
+	if name, ok := mp4Codecs[format]; ok {
+		return name
+	}
+	return format
+}
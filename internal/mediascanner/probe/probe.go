@@ -0,0 +1,73 @@
+// Package probe reads the technical metadata of Matroska and MP4 files from
+// their container headers, without decoding any frame or calling external
+// tools.
+package probe
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nouuu/gonamer/internal/mediascanner"
+)
+
+var ErrUnsupported = errors.New("unsupported container")
+
+// maxElementSize bounds the header elements read in memory, so that a corrupt
+// size can't make the probe allocate gigabytes.
+const maxElementSize = 16 << 20
+
+var ebmlMagic = []byte{0x1A, 0x45, 0xDF, 0xA3}
+
+// File probes the container at path.
+func File(path string) (mediascanner.MediaInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return mediascanner.MediaInfo{}, err
+	}
+	defer f.Close()
+
+	info, err := Reader(f)
+	if err != nil {
+		return info, fmt.Errorf("failed to probe %s: %w", path, err)
+	}
+	return info, nil
+}
+
+// Reader probes a Matroska/WebM or MP4/MOV stream.
+func Reader(r io.ReadSeeker) (mediascanner.MediaInfo, error) {
+	header := make([]byte, 12)
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return mediascanner.MediaInfo{}, err
+	}
+	header = header[:n]
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return mediascanner.MediaInfo{}, err
+	}
+
+	switch {
+	case bytes.HasPrefix(header, ebmlMagic):
+		return probeMatroska(r)
+	case len(header) >= 8 && isMP4Box(string(header[4:8])):
+		return probeMP4(r)
+	}
+	return mediascanner.MediaInfo{}, ErrUnsupported
+}
+
+// readAt reads size bytes at offset, refusing unreasonable sizes.
+func readAt(r io.ReadSeeker, offset, size int64) ([]byte, error) {
+	if size < 0 || size > maxElementSize {
+		return nil, fmt.Errorf("element of %d bytes at offset %d is too large", size, offset)
+	}
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
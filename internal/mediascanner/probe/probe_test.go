@@ -0,0 +1,187 @@
+package probe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/nouuu/gonamer/internal/mediascanner"
+)
+
+// ebml encodes an element with a 4-byte size; children are concatenated.
+func ebml(id uint64, children ...[]byte) []byte {
+	data := bytes.Join(children, nil)
+	var out []byte
+	for shift := 24; shift >= 0; shift -= 8 {
+		if b := byte(id >> uint(shift)); b != 0 || len(out) > 0 {
+			out = append(out, b)
+		}
+	}
+	size := make([]byte, 4)
+	binary.BigEndian.PutUint32(size, uint32(len(data))|0x10000000)
+	return append(append(out, size...), data...)
+}
+
+func ebmlUint(id uint64, v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return ebml(id, b)
+}
+
+func ebmlFloat(id uint64, v float64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, math.Float64bits(v))
+	return ebml(id, b)
+}
+
+func ebmlString(id uint64, s string) []byte {
+	return ebml(id, []byte(s))
+}
+
+func TestMatroska(t *testing.T) {
+	file := bytes.Join([][]byte{
+		ebml(idEBML, ebmlString(0x4282, "matroska")),
+		ebml(idSegment,
+			ebml(idInfo, ebmlUint(idTimecodeScale, 1000000), ebmlFloat(idDuration, 42*60*1000)),
+			ebml(idTracks,
+				ebml(idTrackEntry,
+					ebmlUint(idTrackType, trackTypeVideo),
+					ebmlString(idCodecID, "V_MPEGH/ISO/HEVC"),
+					ebml(idVideo, ebmlUint(idPixelWidth, 1920), ebmlUint(idPixelHeight, 800)),
+				),
+				ebml(idTrackEntry,
+					ebmlUint(idTrackType, trackTypeAudio),
+					ebmlString(idCodecID, "A_EAC3"),
+					ebmlString(idLanguage, "fre"),
+					ebmlUint(idFlagDefault, 0),
+					ebml(idAudio, ebmlUint(idChannels, 6)),
+				),
+				ebml(idTrackEntry,
+					ebmlUint(idTrackType, trackTypeAudio),
+					ebmlString(idCodecID, "A_AAC"),
+					ebml(idAudio, ebmlUint(idChannels, 2)),
+				),
+				ebml(idTrackEntry,
+					ebmlUint(idTrackType, trackTypeSubtitle),
+					ebmlString(idCodecID, "S_TEXT/UTF8"),
+					ebmlString(idLanguage, "tur"),
+					ebmlUint(idFlagForced, 1),
+				),
+			),
+			ebml(idCluster, []byte{0, 0, 0, 0}),
+		),
+	}, nil)
+
+	info, err := Reader(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("Reader() error = %v", err)
+	}
+
+	want := mediascanner.MediaInfo{
+		Duration:   42 * time.Minute,
+		Width:      1920,
+		Height:     800,
+		VideoCodec: "x265",
+		AudioTracks: []mediascanner.AudioTrack{
+			{Codec: "EAC3", Language: "fre", Channels: 6},
+			{Codec: "AAC", Language: "eng", Channels: 2, Default: true},
+		},
+		Subtitles: []mediascanner.SubtitleTrack{{Codec: "SRT", Language: "tur", Forced: true}},
+	}
+	assertInfo(t, info, want)
+	if info.Resolution() != "1080p" {
+		t.Errorf("Resolution() = %s, want 1080p", info.Resolution())
+	}
+	if audio, _ := info.MainAudio(); audio.Codec != "AAC" || audio.Layout() != "2.0" {
+		t.Errorf("MainAudio() = %+v, want the default AAC 2.0 track", audio)
+	}
+}
+
+func mp4Box(typ string, children ...[]byte) []byte {
+	data := bytes.Join(children, nil)
+	out := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint32(out, uint32(8+len(data)))
+	copy(out[4:], typ)
+	return append(out, data...)
+}
+
+func u16(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+func u32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+
+func mp4TrackBox(handler, format string, language string, entryFields []byte) []byte {
+	packed := uint16(0)
+	for _, c := range []byte(language) {
+		packed = packed<<5 | uint16(c-0x60)
+	}
+	sampleEntry := mp4Box(format, make([]byte, 6), u16(1), entryFields)
+	return mp4Box("trak",
+		mp4Box("tkhd", []byte{0, 0, 0, 1}),
+		mp4Box("mdia",
+			mp4Box("mdhd", make([]byte, 12), u32(1000), u32(0), u16(packed), u16(0)),
+			mp4Box("hdlr", make([]byte, 8), []byte(handler), make([]byte, 12)),
+			mp4Box("minf", mp4Box("stbl", mp4Box("stsd", make([]byte, 4), u32(1), sampleEntry))),
+		),
+	)
+}
+
+func TestMP4(t *testing.T) {
+	video := append(make([]byte, 16), append(u16(3840), u16(2160)...)...)
+	audio := append(make([]byte, 8), u16(2)...)
+
+	file := bytes.Join([][]byte{
+		mp4Box("ftyp", []byte("isom"), u32(0)),
+		mp4Box("mdat", make([]byte, 32)),
+		mp4Box("moov",
+			mp4Box("mvhd", make([]byte, 12), u32(600), u32(600*128*60)),
+			mp4TrackBox("vide", "hvc1", "und", video),
+			mp4TrackBox("soun", "ec-3", "eng", audio),
+			mp4TrackBox("text", "tx3g", "fra", nil),
+		),
+	}, nil)
+
+	info, err := Reader(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("Reader() error = %v", err)
+	}
+
+	assertInfo(t, info, mediascanner.MediaInfo{
+		Duration:    128 * time.Minute,
+		Width:       3840,
+		Height:      2160,
+		VideoCodec:  "x265",
+		AudioTracks: []mediascanner.AudioTrack{{Codec: "EAC3", Language: "eng", Channels: 2, Default: true}},
+		Subtitles:   []mediascanner.SubtitleTrack{{Codec: "TX3G", Language: "fra"}},
+	})
+}
+
+func TestUnsupported(t *testing.T) {
+	if _, err := Reader(bytes.NewReader([]byte("RIFF....AVI LIST"))); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Reader() error = %v, want ErrUnsupported", err)
+	}
+}
+
+func assertInfo(t *testing.T, got, want mediascanner.MediaInfo) {
+	t.Helper()
+	if got.Duration != want.Duration || got.Width != want.Width || got.Height != want.Height || got.VideoCodec != want.VideoCodec {
+		t.Errorf("video = %v %dx%d %s, want %v %dx%d %s", got.Duration, got.Width, got.Height, got.VideoCodec, want.Duration, want.Width, want.Height, want.VideoCodec)
+	}
+	if len(got.AudioTracks) != len(want.AudioTracks) {
+		t.Fatalf("audio tracks = %+v, want %+v", got.AudioTracks, want.AudioTracks)
+	}
+	for i := range want.AudioTracks {
+		if got.AudioTracks[i] != want.AudioTracks[i] {
+			t.Errorf("audio track %d = %+v, want %+v", i, got.AudioTracks[i], want.AudioTracks[i])
+		}
+	}
+	if len(got.Subtitles) != len(want.Subtitles) {
+		t.Fatalf("subtitles = %+v, want %+v", got.Subtitles, want.Subtitles)
+	}
+	for i := range want.Subtitles {
+		if got.Subtitles[i] != want.Subtitles[i] {
+			t.Errorf("subtitle %d = %+v, want %+v", i, got.Subtitles[i], want.Subtitles[i])
+		}
+	}
+}
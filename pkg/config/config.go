@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/nouuu/gonamer/pkg/sanitize"
 	"gopkg.in/yaml.v3"
 )
 
@@ -18,16 +20,43 @@ var defaultConfig = Config{
 		MediaPath:       "./",
 		Recursive:       true,
 		IncludeNotFound: false,
+		Casing:          DefaultCasing,
 	},
 	Renamer: RenamerConfig{
-		DryRun:     true,
-		Type:       Movie,
-		MaxResults: 5,
-		QuickMode:  false,
+		DryRun:        true,
+		Type:          Movie,
+		MaxResults:    5,
+		SearchDepth:   1,
+		QuickMode:     false,
+		RetryFile:     "gonamer-retry.txt",
+		OnConflict:    ConflictSuffix,
+		QuarantineDir: "gonamer-quarantine",
+		Duplicates:    DuplicatesAsk,
+		Relaxation: []RelaxStep{RelaxNoYear, RelaxYearRange, RelaxBeforeDash,
+			RelaxAkaTitles, RelaxAlternativeTitles, RelaxDropTokens},
 		Patterns: PatternConfig{
 			Movie:  "{name} - {year}{extension}",
 			TVShow: "{name} - {season}x{episode}{extension}",
 		},
+		Sanitize: SanitizeConfig{
+			Mode:      sanitize.Windows,
+			MaxLength: sanitize.DefaultMaxLength,
+		},
+	},
+	Cache: CacheConfig{
+		Type: FileCache,
+		File: "gonamer-cache.gob",
+		TTL: CacheTTLConfig{
+			Search:   time.Hour,
+			Movie:    24 * time.Hour,
+			TvShow:   24 * time.Hour,
+			Season:   24 * time.Hour,
+			Negative: 24 * time.Hour,
+		},
+		Redis: RedisConfig{
+			Address: "localhost:6379",
+			Prefix:  "gonamer:",
+		},
 	},
 }
 
@@ -38,10 +67,49 @@ const (
 	TvShow MediaType = "tvshow"
 )
 
+// ConflictPolicy tells what to do when the destination of a rename already
+// exists.
+type ConflictPolicy string
+
+const (
+	ConflictSuffix            ConflictPolicy = "suffix"
+	ConflictSkip              ConflictPolicy = "skip"
+	ConflictOverwrite         ConflictPolicy = "overwrite"
+	ConflictKeepLarger        ConflictPolicy = "keep-larger"
+	ConflictKeepHigherQuality ConflictPolicy = "keep-higher-quality"
+	ConflictAsk               ConflictPolicy = "ask"
+	ConflictQuarantine        ConflictPolicy = "quarantine"
+)
+
+// DuplicatePolicy tells which file to keep when several files of a run match
+// the same movie or episode. The others are moved to the quarantine folder.
+type DuplicatePolicy string
+
+const (
+	DuplicatesAsk               DuplicatePolicy = "ask"
+	DuplicatesKeepHigherQuality DuplicatePolicy = "keep-higher-quality"
+	DuplicatesKeepLarger        DuplicatePolicy = "keep-larger"
+	DuplicatesKeepAll           DuplicatePolicy = "keep-all"
+)
+
+// RelaxStep is a fallback search tried, in order, when searching a movie by
+// its name and year finds nothing.
+type RelaxStep string
+
+const (
+	RelaxNoYear            RelaxStep = "no-year"
+	RelaxYearRange         RelaxStep = "year-range"
+	RelaxDropTokens        RelaxStep = "drop-tokens"
+	RelaxBeforeDash        RelaxStep = "before-dash"
+	RelaxAkaTitles         RelaxStep = "aka-titles"
+	RelaxAlternativeTitles RelaxStep = "alternative-titles"
+)
+
 type Config struct {
 	API     APIConfig     `yaml:"api"`
 	Scanner ScannerConfig `yaml:"scanner"`
 	Renamer RenamerConfig `yaml:"renamer"`
+	Cache   CacheConfig   `yaml:"cache"`
 }
 
 type APIConfig struct {
@@ -51,6 +119,28 @@ type APIConfig struct {
 type TMDBConfig struct {
 	Key      string `yaml:"key"`
 	Language string `yaml:"language"`
+	Offline  bool   `yaml:"offline"`
+	// SearchLanguages are tried in order until a search finds results, and
+	// NamingLanguage is the one titles are fetched in. Both default to
+	// Language.
+	SearchLanguages []string `yaml:"search_languages,omitempty"`
+	NamingLanguage  string   `yaml:"naming_language,omitempty"`
+}
+
+// SearchLangs returns the languages searches are tried in.
+func (c TMDBConfig) SearchLangs() []string {
+	if len(c.SearchLanguages) == 0 {
+		return []string{c.Language}
+	}
+	return c.SearchLanguages
+}
+
+// NamingLang returns the language of the titles used in new names.
+func (c TMDBConfig) NamingLang() string {
+	if c.NamingLanguage == "" {
+		return c.Language
+	}
+	return c.NamingLanguage
 }
 
 type ScannerConfig struct {
@@ -59,20 +149,116 @@ type ScannerConfig struct {
 	IncludeNotFound bool   `yaml:"include_not_found"`
 	ExcludeUnparsed bool     `yaml:"exclude_unparsed,omitempty"`
 	DeleteKeywords  []string `yaml:"delete_keywords,omitempty"`
+	DisableProbe    bool     `yaml:"disable_probe"`
+	// DisableIDs ignores the IMDb, TMDB and TVDB IDs of file names, folders
+	// and .nfo files, and always searches by name.
+	DisableIDs bool `yaml:"disable_ids"`
+	// Casing is the locale parsed names are title-cased in, a BCP 47 tag
+	// such as "en" or "tr", or CasingNone to keep their case.
+	Casing string `yaml:"casing,omitempty"`
 }
 
+const (
+	// DefaultCasing title-cases parsed names the Turkish way.
+	DefaultCasing = "tr"
+	// CasingNone keeps the case of parsed names.
+	CasingNone = "none"
+)
+
 
 type RenamerConfig struct {
-	DryRun     bool          `yaml:"dry_run"`
-	Type       MediaType     `yaml:"type"`
-	Patterns   PatternConfig `yaml:"patterns"`
-	MaxResults int           `yaml:"max_results"`
-	QuickMode  bool          `yaml:"quick_mode"`
+	DryRun     bool           `yaml:"dry_run"`
+	Type       MediaType      `yaml:"type"`
+	Patterns   PatternConfig  `yaml:"patterns"`
+	MaxResults int            `yaml:"max_results"`
+	QuickMode  bool           `yaml:"quick_mode"`
+	RetryFile  string         `yaml:"retry_file"`
+	Sanitize   SanitizeConfig `yaml:"sanitize"`
+	// OnConflict applies when a destination file already exists. The file
+	// losing a quarantine conflict is moved to QuarantineDir, which is
+	// relative to the media path unless absolute.
+	OnConflict    ConflictPolicy  `yaml:"on_conflict"`
+	QuarantineDir string          `yaml:"quarantine_dir"`
+	Duplicates    DuplicatePolicy `yaml:"duplicates"`
+	Routes        []RouteRule     `yaml:"routes,omitempty"`
+	Sort          SortConfig      `yaml:"sort,omitempty"`
+	// Relaxation lists the fallback searches of movies without results. It
+	// defaults to all of them, an empty list disables them.
+	Relaxation []RelaxStep `yaml:"relaxation"`
+	// SearchDepth is the number of pages of TMDB results ranked when
+	// matching files, 20 results each.
+	SearchDepth int `yaml:"search_depth"`
+
+	// configuredQuarantineDir is QuarantineDir as written in the
+	// configuration, before it is resolved against the media path.
+	configuredQuarantineDir string
 }
 
+
 type PatternConfig struct {
+	// Preset builds the movie and tv show patterns left empty from the
+	// layout of a media server. Its parts can be overridden one by one.
+	Preset Preset `yaml:"preset,omitempty"`
 	Movie  string `yaml:"movie"`
 	TVShow string `yaml:"tvshow"`
+	// Root is the folder relative patterns are resolved against, instead of
+	// the folder of each file. It defaults to the media path with a preset.
+	Root string `yaml:"root,omitempty"`
+
+	MovieFolder  string `yaml:"movie_folder,omitempty"`
+	MovieFile    string `yaml:"movie_file,omitempty"`
+	Extra        string `yaml:"extra,omitempty"`
+	ShowFolder   string `yaml:"show_folder,omitempty"`
+	SeasonFolder string `yaml:"season_folder,omitempty"`
+	EpisodeFile  string `yaml:"episode_file,omitempty"`
+}
+
+// SortConfig controls the {sort_name} and {initial} fields. Language picks
+// the articles moved to the end of titles and defaults to the TMDB language;
+// Articles replaces the articles of the languages it lists.
+type SortConfig struct {
+	Language string              `yaml:"language,omitempty"`
+	Articles map[string][]string `yaml:"articles,omitempty"`
+}
+
+// SanitizeConfig controls how generated names are made safe for the target
+// filesystem. MaxLength is the maximum size of a path component in bytes.
+type SanitizeConfig struct {
+	Mode      sanitize.Mode `yaml:"mode"`
+	MaxLength int           `yaml:"max_length"`
+}
+
+type CacheType string
+
+const (
+	FileCache  CacheType = "file"
+	RedisCache CacheType = "redis"
+	NoCache    CacheType = "none"
+)
+
+type CacheConfig struct {
+	Type  CacheType      `yaml:"type"`
+	File  string         `yaml:"file"`
+	TTL   CacheTTLConfig `yaml:"ttl"`
+	Redis RedisConfig    `yaml:"redis"`
+}
+
+// CacheTTLConfig holds the expiration applied to each family of cache entries.
+// Negative applies to searches that returned nothing; set it below zero to
+// stop caching them.
+type CacheTTLConfig struct {
+	Search   time.Duration `yaml:"search"`
+	Movie    time.Duration `yaml:"movie"`
+	TvShow   time.Duration `yaml:"tvshow"`
+	Season   time.Duration `yaml:"season"`
+	Negative time.Duration `yaml:"negative"`
+}
+
+type RedisConfig struct {
+	Address  string `yaml:"address"`
+	Password string `yaml:"password"`
+	DB       int    `yaml:"db"`
+	Prefix   string `yaml:"prefix"`
 }
 
 // LoadConfig loads the configuration from a YAML file
@@ -101,11 +287,30 @@ func LoadConfig(configPath string) (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid media path: %w", err)
 	}
-	cfg.Scanner.MediaPath = absPath
+	cfg.SetMediaPath(absPath)
 
 	return &cfg, nil
 }
 
+// SetMediaPath changes the scanned folder, and with it the quarantine folder
+// when it is relative.
+func (c *Config) SetMediaPath(path string) {
+	c.Scanner.MediaPath = path
+	c.resolveQuarantineDir()
+}
+
+// resolveQuarantineDir places a relative quarantine_dir in the media path
+// rather than in the working directory.
+func (c *Config) resolveQuarantineDir() {
+	if c.Renamer.configuredQuarantineDir == "" {
+		c.Renamer.configuredQuarantineDir = c.Renamer.QuarantineDir
+	}
+	c.Renamer.QuarantineDir = c.Renamer.configuredQuarantineDir
+	if !filepath.IsAbs(c.Renamer.QuarantineDir) {
+		c.Renamer.QuarantineDir = filepath.Join(c.Scanner.MediaPath, c.Renamer.QuarantineDir)
+	}
+}
+
 // CreateDefaultConfig creates a default configuration file
 func CreateDefaultConfig(path string) error {
 	cfg := defaultConfig
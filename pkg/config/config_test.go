@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/nouuu/gonamer/pkg/pattern"
 	"gopkg.in/yaml.v3"
 )
 
@@ -111,6 +112,22 @@ func TestValidateConfig(t *testing.T) {
 			},
 			shouldError: true,
 		},
+		{
+			name: "Routing rule without destination",
+			config: Config{
+				API: APIConfig{
+					TMDB: TMDBConfig{
+						Key: "valid-key",
+					},
+				},
+				Renamer: RenamerConfig{
+					Type:     Movie,
+					Patterns: defaultConfig.Renamer.Patterns,
+					Routes:   []RouteRule{{Name: "docs", Match: RouteMatch{Genres: []string{"Documentary"}}}},
+				},
+			},
+			shouldError: true,
+		},
 		{
 			name: "Invalid media type",
 			config: Config{
@@ -218,3 +235,101 @@ func TestCreateDefaultConfig(t *testing.T) {
 		t.Errorf("Default type = %v, want %v", cfg.Renamer.Type, defaultConfig.Renamer.Type)
 	}
 }
+
+func TestPresets(t *testing.T) {
+	movie := pattern.Values{
+		pattern.FieldName:    pattern.Text("Blade Runner"),
+		pattern.FieldYear:    pattern.Text("1982"),
+		pattern.FieldTmdbID:  pattern.Text("78"),
+		pattern.FieldEdition: pattern.Text("Director's Cut"),
+		pattern.FieldExt:     pattern.Text(".mkv"),
+	}
+	episode := pattern.Values{
+		pattern.FieldName:         pattern.Text("Breaking Bad"),
+		pattern.FieldYear:         pattern.Text("2008"),
+		pattern.FieldTmdbID:       pattern.Text("1396"),
+		pattern.FieldSeason:       pattern.PaddedNumber(1, 2),
+		pattern.FieldEpisode:      pattern.PaddedNumber(1, 2),
+		pattern.FieldEpisodeEnd:   pattern.PaddedNumber(2, 2),
+		pattern.FieldEpisodeTitle: pattern.Text("Pilot"),
+		pattern.FieldExt:          pattern.Text(".mkv"),
+	}
+	tests := []struct {
+		patterns      PatternConfig
+		movie, tvshow string
+	}{
+		{
+			patterns: PatternConfig{Preset: PresetPlex},
+			movie:    "Blade Runner (1982) {tmdb-78}/Blade Runner (1982) {edition-Director's Cut}.mkv",
+			tvshow:   "Breaking Bad (2008) {tmdb-1396}/Season 01/Breaking Bad - S01E01-E02 - Pilot.mkv",
+		},
+		{
+			patterns: PatternConfig{Preset: PresetJellyfin},
+			movie:    "Blade Runner (1982) [tmdbid-78]/Blade Runner (1982) - Director's Cut.mkv",
+			tvshow:   "Breaking Bad (2008) [tmdbid-1396]/Season 01/Breaking Bad S01E01-E02 - Pilot.mkv",
+		},
+		{
+			patterns: PatternConfig{Preset: PresetKodi, SeasonFolder: "Season {season:1}"},
+			movie:    "Blade Runner (1982)/Blade Runner (1982) - Director's Cut.mkv",
+			tvshow:   "Breaking Bad (2008)/Season 1/Breaking Bad S01E01E02 - Pilot.mkv",
+		},
+		{
+			patterns: PatternConfig{Preset: PresetEmby, Movie: "{name} ({year}){extension}"},
+			movie:    "Blade Runner (1982).mkv",
+			tvshow:   "Breaking Bad (2008) [tmdbid=1396]/Season 01/Breaking Bad - S01E01-E02 - Pilot.mkv",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.patterns.Preset), func(t *testing.T) {
+			cfg := Config{API: APIConfig{TMDB: TMDBConfig{Key: "key"}}, Renamer: RenamerConfig{Type: Movie, Patterns: tt.patterns}}
+			cfg.applyDefaults()
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			for _, c := range []struct {
+				source string
+				fields []string
+				values pattern.Values
+				want   string
+			}{
+				{cfg.Renamer.Patterns.Movie, pattern.MovieFields, movie, tt.movie},
+				{cfg.Renamer.Patterns.TVShow, pattern.EpisodeFields, episode, tt.tvshow},
+			} {
+				tmpl, err := pattern.Parse(c.source, c.fields)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := tmpl.Execute(c.values); got != c.want {
+					t.Errorf("Execute(%q) = %q, want %q", c.source, got, c.want)
+				}
+			}
+		})
+	}
+
+	cfg := Config{API: APIConfig{TMDB: TMDBConfig{Key: "key"}}, Renamer: RenamerConfig{Patterns: PatternConfig{Preset: "infuse"}}}
+	cfg.applyDefaults()
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() accepted an unknown preset")
+	}
+}
+
+func TestQuarantineDir(t *testing.T) {
+	cfg := Config{Scanner: ScannerConfig{MediaPath: "/media/movies"}}
+	cfg.applyDefaults()
+	if want := filepath.Join("/media/movies", "gonamer-quarantine"); cfg.Renamer.QuarantineDir != want {
+		t.Errorf("QuarantineDir = %q, want %q", cfg.Renamer.QuarantineDir, want)
+	}
+
+	cfg.SetMediaPath("/mnt/downloads")
+	if want := filepath.Join("/mnt/downloads", "gonamer-quarantine"); cfg.Renamer.QuarantineDir != want {
+		t.Errorf("QuarantineDir after SetMediaPath() = %q, want %q", cfg.Renamer.QuarantineDir, want)
+	}
+
+	cfg = Config{Scanner: ScannerConfig{MediaPath: "/media/movies"}, Renamer: RenamerConfig{QuarantineDir: "/srv/quarantine"}}
+	cfg.applyDefaults()
+	cfg.SetMediaPath("/mnt/downloads")
+	if cfg.Renamer.QuarantineDir != "/srv/quarantine" {
+		t.Errorf("QuarantineDir = %q, want the absolute folder kept", cfg.Renamer.QuarantineDir)
+	}
+}
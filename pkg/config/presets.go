@@ -0,0 +1,86 @@
+package config
+
+// Preset is a media server whose recommended library layout can be used
+// instead of hand-written patterns.
+type Preset string
+
+const (
+	PresetPlex     Preset = "plex"
+	PresetJellyfin Preset = "jellyfin"
+	PresetKodi     Preset = "kodi"
+	PresetEmby     Preset = "emby"
+)
+
+// Specials (season 0) go to a "Specials" folder, which every server accepts.
+const seasonFolder = "{if season}Season {season}{else}Specials{end}"
+
+// presets holds the parts of each layout. Provider IDs are added to the
+// folder names where the server reads them; Kodi reads them from .nfo files.
+var presets = map[Preset]PatternConfig{
+	PresetPlex: {
+		MovieFolder:  "{name}{if year} ({year}){end} {{tmdb-{tmdb_id}}}",
+		MovieFile:    "{name}{if year} ({year}){end}{if edition} {{edition-{edition}}}{end}{if part} - pt{part}{end}",
+		Extra:        "{extra}/{original_filename}",
+		ShowFolder:   "{name}{if year} ({year}){end} {{tmdb-{tmdb_id}}}",
+		SeasonFolder: seasonFolder,
+		EpisodeFile:  "{name} - S{season}E{episode}{if episode_end}-E{episode_end}{end}{if episode_title} - {episode_title}{end}",
+	},
+	PresetJellyfin: {
+		MovieFolder:  "{name}{if year} ({year}){end} [tmdbid-{tmdb_id}]",
+		MovieFile:    "{name}{if year} ({year}){end}{if edition} - {edition}{end}{if part}-pt{part}{end}",
+		Extra:        "{extra}/{original_filename}",
+		ShowFolder:   "{name}{if year} ({year}){end} [tmdbid-{tmdb_id}]",
+		SeasonFolder: seasonFolder,
+		EpisodeFile:  "{name} S{season}E{episode}{if episode_end}-E{episode_end}{end}{if episode_title} - {episode_title}{end}",
+	},
+	PresetKodi: {
+		MovieFolder:  "{name}{if year} ({year}){end}",
+		MovieFile:    "{name}{if year} ({year}){end}{if edition} - {edition}{end}{if part} - pt{part}{end}",
+		Extra:        "Extras/{original_filename}",
+		ShowFolder:   "{name}{if year} ({year}){end}",
+		SeasonFolder: seasonFolder,
+		EpisodeFile:  "{name} S{season}E{episode}{if episode_end}E{episode_end}{end}{if episode_title} - {episode_title}{end}",
+	},
+	PresetEmby: {
+		MovieFolder:  "{name}{if year} ({year}){end} [tmdbid={tmdb_id}]",
+		MovieFile:    "{name}{if year} ({year}){end}{if edition} - {edition}{end}{if part} - pt{part}{end}",
+		Extra:        "{extra}/{original_filename}",
+		ShowFolder:   "{name}{if year} ({year}){end} [tmdbid={tmdb_id}]",
+		SeasonFolder: seasonFolder,
+		EpisodeFile:  "{name} - S{season}E{episode}{if episode_end}-E{episode_end}{end}{if episode_title} - {episode_title}{end}",
+	},
+}
+
+func (p Preset) IsValid() bool {
+	_, ok := presets[p]
+	return ok
+}
+
+// withPreset fills the parts left empty from the preset, then builds the movie
+// and tv show patterns that weren't set explicitly.
+func (p PatternConfig) withPreset() PatternConfig {
+	preset, ok := presets[p.Preset]
+	if !ok {
+		return p
+	}
+	for _, part := range []struct{ value, fallback *string }{
+		{&p.MovieFolder, &preset.MovieFolder},
+		{&p.MovieFile, &preset.MovieFile},
+		{&p.Extra, &preset.Extra},
+		{&p.ShowFolder, &preset.ShowFolder},
+		{&p.SeasonFolder, &preset.SeasonFolder},
+		{&p.EpisodeFile, &preset.EpisodeFile},
+	} {
+		if *part.value == "" {
+			*part.value = *part.fallback
+		}
+	}
+
+	if p.Movie == "" {
+		p.Movie = p.MovieFolder + "/{if extra}" + p.Extra + "{else}" + p.MovieFile + "{end}{extension}"
+	}
+	if p.TVShow == "" {
+		p.TVShow = p.ShowFolder + "/" + p.SeasonFolder + "/" + p.EpisodeFile + "{extension}"
+	}
+	return p
+}
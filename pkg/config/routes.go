@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/nouuu/gonamer/pkg/pattern"
+)
+
+// RouteRule sends the items matching its conditions to another root, or
+// renames them with another pattern. Rules are tried in order after a match is
+// chosen and the first one matching wins.
+type RouteRule struct {
+	Name  string     `yaml:"name"`
+	Match RouteMatch `yaml:"match"`
+	// Root replaces the folder relative patterns are resolved against.
+	Root string `yaml:"root,omitempty"`
+	// Pattern replaces the movie or tv show pattern.
+	Pattern string `yaml:"pattern,omitempty"`
+}
+
+// RouteMatch holds the conditions of a rule, which must all hold. A list
+// matches when the item has one of its values; values starting with "!" must
+// not be found, e.g. original_language: ["!en"] for foreign films. Zero
+// bounds are ignored.
+type RouteMatch struct {
+	Type             MediaType `yaml:"type,omitempty"`
+	Genres           []string  `yaml:"genres,omitempty"`
+	OriginalLanguage []string  `yaml:"original_language,omitempty"`
+	Certifications   []string  `yaml:"certifications,omitempty"`
+	Keywords         []string  `yaml:"keywords,omitempty"`
+	MinRating        float32   `yaml:"min_rating,omitempty"`
+	MaxRating        float32   `yaml:"max_rating,omitempty"`
+	MinYear          int       `yaml:"min_year,omitempty"`
+	MaxYear          int       `yaml:"max_year,omitempty"`
+	MinRuntime       int       `yaml:"min_runtime,omitempty"`
+	MaxRuntime       int       `yaml:"max_runtime,omitempty"`
+}
+
+// validateRoutes checks every rule routes somewhere and that its pattern is
+// valid for the media type it applies to.
+func (c *Config) validateRoutes() ValidationErrors {
+	var errs ValidationErrors
+	for i, rule := range c.Renamer.Routes {
+		field := fmt.Sprintf("renamer.routes[%d]", i)
+		if rule.Name == "" {
+			errs = append(errs, ValidationError{Field: field + ".name", Message: "every routing rule needs a name"})
+		}
+		if rule.Root == "" && rule.Pattern == "" {
+			errs = append(errs, ValidationError{Field: field, Message: "routing rule needs a root or a pattern"})
+		}
+		if rule.Match.Type != "" && !isValidMediaType(rule.Match.Type) {
+			errs = append(errs, ValidationError{Field: field + ".match.type", Message: "invalid media type, must be 'movie' or 'tvshow'"})
+		}
+		if rule.Pattern == "" {
+			continue
+		}
+		kind := rule.Match.Type
+		if kind == "" {
+			kind = c.Renamer.Type
+		}
+		if kind == TvShow {
+			errs = append(errs, validatePattern(field+".pattern", "tv show", rule.Pattern,
+				pattern.EpisodeFields, []string{pattern.FieldName, pattern.FieldSeason, pattern.FieldEpisode, pattern.FieldExt})...)
+		} else {
+			errs = append(errs, validatePattern(field+".pattern", "movie", rule.Pattern,
+				pattern.MovieFields, []string{pattern.FieldName, pattern.FieldYear, pattern.FieldExt})...)
+		}
+	}
+	return errs
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"strings"
+
+	"github.com/nouuu/gonamer/pkg/pattern"
+	"golang.org/x/text/language"
 )
 
 // ValidationError represents a configuration validation error
@@ -40,10 +43,20 @@ func (c *Config) applyDefaults() {
 		c.Scanner.MediaPath = defaultConfig.Scanner.MediaPath
 	}
 
+	if c.Scanner.Casing == "" {
+		c.Scanner.Casing = defaultConfig.Scanner.Casing
+	}
+
 	if c.Renamer.MaxResults <= 0 {
 		c.Renamer.MaxResults = defaultConfig.Renamer.MaxResults
 	}
 
+	if c.Renamer.SearchDepth <= 0 {
+		c.Renamer.SearchDepth = defaultConfig.Renamer.SearchDepth
+	}
+
+	c.Renamer.Patterns = c.Renamer.Patterns.withPreset()
+
 	if c.Renamer.Patterns.Movie == "" {
 		c.Renamer.Patterns.Movie = defaultConfig.Renamer.Patterns.Movie
 	}
@@ -51,6 +64,71 @@ func (c *Config) applyDefaults() {
 	if c.Renamer.Patterns.TVShow == "" {
 		c.Renamer.Patterns.TVShow = defaultConfig.Renamer.Patterns.TVShow
 	}
+
+	if c.Renamer.RetryFile == "" {
+		c.Renamer.RetryFile = defaultConfig.Renamer.RetryFile
+	}
+
+	if c.Renamer.OnConflict == "" {
+		c.Renamer.OnConflict = defaultConfig.Renamer.OnConflict
+	}
+
+	if c.Renamer.QuarantineDir == "" {
+		c.Renamer.QuarantineDir = defaultConfig.Renamer.QuarantineDir
+	}
+	c.resolveQuarantineDir()
+
+	if c.Renamer.Duplicates == "" {
+		c.Renamer.Duplicates = defaultConfig.Renamer.Duplicates
+	}
+
+	if c.Renamer.Relaxation == nil {
+		c.Renamer.Relaxation = defaultConfig.Renamer.Relaxation
+	}
+
+	if c.Renamer.Sanitize.Mode == "" {
+		c.Renamer.Sanitize.Mode = defaultConfig.Renamer.Sanitize.Mode
+	}
+
+	if c.Renamer.Sanitize.MaxLength <= 0 {
+		c.Renamer.Sanitize.MaxLength = defaultConfig.Renamer.Sanitize.MaxLength
+	}
+
+	if c.Cache.Type == "" {
+		c.Cache.Type = defaultConfig.Cache.Type
+	}
+
+	if c.Cache.File == "" {
+		c.Cache.File = defaultConfig.Cache.File
+	}
+
+	if c.Cache.TTL.Search <= 0 {
+		c.Cache.TTL.Search = defaultConfig.Cache.TTL.Search
+	}
+
+	if c.Cache.TTL.Movie <= 0 {
+		c.Cache.TTL.Movie = defaultConfig.Cache.TTL.Movie
+	}
+
+	if c.Cache.TTL.TvShow <= 0 {
+		c.Cache.TTL.TvShow = defaultConfig.Cache.TTL.TvShow
+	}
+
+	if c.Cache.TTL.Season <= 0 {
+		c.Cache.TTL.Season = defaultConfig.Cache.TTL.Season
+	}
+
+	if c.Cache.TTL.Negative == 0 {
+		c.Cache.TTL.Negative = defaultConfig.Cache.TTL.Negative
+	}
+
+	if c.Cache.Redis.Address == "" {
+		c.Cache.Redis.Address = defaultConfig.Cache.Redis.Address
+	}
+
+	if c.Cache.Redis.Prefix == "" {
+		c.Cache.Redis.Prefix = defaultConfig.Cache.Redis.Prefix
+	}
 }
 
 // validate performs comprehensive validation of the configuration
@@ -66,17 +144,25 @@ func (c *Config) Validate() error {
 	}
 
 	// Validate patterns
-	if !hasValidPatternVariables(c.Renamer.Patterns.Movie, []string{"{name}", "{year}", "{extension}"}) {
+	if c.Renamer.Patterns.Preset != "" && !c.Renamer.Patterns.Preset.IsValid() {
 		errs = append(errs, ValidationError{
-			Field:   "renamer.patterns.movie",
-			Message: "movie pattern must contain {name}, {year}, and {extension}",
+			Field:   "renamer.patterns.preset",
+			Message: "invalid preset, must be 'plex', 'jellyfin', 'kodi' or 'emby'",
 		})
 	}
 
-	if !hasValidPatternVariables(c.Renamer.Patterns.TVShow, []string{"{name}", "{season}", "{episode}", "{extension}"}) {
+	errs = append(errs, validatePattern("renamer.patterns.movie", "movie", c.Renamer.Patterns.Movie,
+		pattern.MovieFields, []string{pattern.FieldName, pattern.FieldYear, pattern.FieldExt})...)
+
+	errs = append(errs, validatePattern("renamer.patterns.tvshow", "tv show", c.Renamer.Patterns.TVShow,
+		pattern.EpisodeFields, []string{pattern.FieldName, pattern.FieldSeason, pattern.FieldEpisode, pattern.FieldExt})...)
+
+	errs = append(errs, c.validateRoutes()...)
+
+	if !isValidCasing(c.Scanner.Casing) {
 		errs = append(errs, ValidationError{
-			Field:   "renamer.patterns.tvshow",
-			Message: "tv show pattern must contain {name}, {season}, {episode}, and {extension}",
+			Field:   "scanner.casing",
+			Message: fmt.Sprintf("invalid casing %q, must be a language tag such as 'en' or 'tr', or 'none'", c.Scanner.Casing),
 		})
 	}
 
@@ -96,6 +182,58 @@ func (c *Config) Validate() error {
 		})
 	}
 
+	if c.Renamer.SearchDepth < 1 {
+		errs = append(errs, ValidationError{
+			Field:   "renamer.search_depth",
+			Message: "search_depth must be greater than 0",
+		})
+	}
+
+	if !isValidConflictPolicy(c.Renamer.OnConflict) {
+		errs = append(errs, ValidationError{
+			Field:   "renamer.on_conflict",
+			Message: "invalid conflict policy, must be 'suffix', 'skip', 'overwrite', 'keep-larger', 'keep-higher-quality', 'ask' or 'quarantine'",
+		})
+	}
+
+	if !isValidDuplicatePolicy(c.Renamer.Duplicates) {
+		errs = append(errs, ValidationError{
+			Field:   "renamer.duplicates",
+			Message: "invalid duplicate policy, must be 'ask', 'keep-higher-quality', 'keep-larger' or 'keep-all'",
+		})
+	}
+
+	for _, step := range c.Renamer.Relaxation {
+		if !isValidRelaxStep(step) {
+			errs = append(errs, ValidationError{
+				Field:   "renamer.relaxation",
+				Message: fmt.Sprintf("invalid relaxation step %q, must be 'no-year', 'year-range', 'drop-tokens', 'before-dash', 'aka-titles' or 'alternative-titles'", step),
+			})
+		}
+	}
+
+	if !c.Renamer.Sanitize.Mode.IsValid() {
+		errs = append(errs, ValidationError{
+			Field:   "renamer.sanitize.mode",
+			Message: "invalid sanitize mode, must be 'posix', 'windows' or 'ascii'",
+		})
+	}
+
+	// Validate cache
+	if !isValidCacheType(c.Cache.Type) {
+		errs = append(errs, ValidationError{
+			Field:   "cache.type",
+			Message: "invalid cache type, must be 'file', 'redis' or 'none'",
+		})
+	}
+
+	if c.Cache.Type == RedisCache && c.Cache.Redis.Address == "" {
+		errs = append(errs, ValidationError{
+			Field:   "cache.redis.address",
+			Message: "redis address is required when cache type is 'redis'",
+		})
+	}
+
 	if len(errs) > 0 {
 		return errs
 	}
@@ -103,15 +241,69 @@ func (c *Config) Validate() error {
 	return nil
 }
 
-func hasValidPatternVariables(pattern string, requiredVars []string) bool {
-	for _, v := range requiredVars {
-		if !strings.Contains(pattern, v) {
-			return false
+// validatePattern parses a renamer pattern and checks it references the
+// required fields, either directly or inside a conditional section.
+func validatePattern(field, kind, source string, fields, required []string) ValidationErrors {
+	tmpl, err := pattern.Parse(source, fields)
+	if err != nil {
+		return ValidationErrors{{
+			Field:   field,
+			Message: fmt.Sprintf("invalid %s pattern %q: %v", kind, source, err),
+		}}
+	}
+
+	var missing []string
+	for _, f := range required {
+		if !tmpl.References(f) {
+			missing = append(missing, "{"+f+"}")
 		}
 	}
-	return true
+	if len(missing) > 0 {
+		return ValidationErrors{{
+			Field:   field,
+			Message: fmt.Sprintf("%s pattern must contain %s", kind, strings.Join(missing, ", ")),
+		}}
+	}
+	return nil
 }
 
 func isValidMediaType(t MediaType) bool {
 	return t == Movie || t == TvShow
 }
+
+func isValidCasing(casing string) bool {
+	if casing == "" || casing == CasingNone {
+		return true
+	}
+	_, err := language.Parse(casing)
+	return err == nil
+}
+
+func isValidCacheType(t CacheType) bool {
+	return t == "" || t == FileCache || t == RedisCache || t == NoCache
+}
+
+func isValidConflictPolicy(p ConflictPolicy) bool {
+	switch p {
+	case "", ConflictSuffix, ConflictSkip, ConflictOverwrite, ConflictKeepLarger,
+		ConflictKeepHigherQuality, ConflictAsk, ConflictQuarantine:
+		return true
+	}
+	return false
+}
+
+func isValidDuplicatePolicy(p DuplicatePolicy) bool {
+	switch p {
+	case "", DuplicatesAsk, DuplicatesKeepHigherQuality, DuplicatesKeepLarger, DuplicatesKeepAll:
+		return true
+	}
+	return false
+}
+
+func isValidRelaxStep(s RelaxStep) bool {
+	switch s {
+	case RelaxNoYear, RelaxYearRange, RelaxDropTokens, RelaxBeforeDash, RelaxAkaTitles, RelaxAlternativeTitles:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+// Package normalize folds titles and file names so that the spellings of a
+// same title compare equal.
+//
+// Names copied from macOS are often NFD-encoded, files are named without
+// accents or in another script than TMDB titles, and sequels are numbered
+// either way: "Amelie" must match "Amélie", "Rocky 2" must match "Rocky II"
+// and "Fast & Furious" must match "Fast and Furious".
+package normalize
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxNumeral bounds the numbers treated as numerals, past the sequels and
+// parts found in titles.
+const maxNumeral = 39
+
+// transliterations covers the letters that don't decompose into an ASCII
+// letter and a combining mark.
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE",
+	'ø': "o", 'Ø': "O", 'ł': "l", 'Ł': "L", 'đ': "d", 'Đ': "D",
+	'ð': "d", 'Ð': "D", 'þ': "th", 'Þ': "Th", 'ı': "i",
+	'‘': "'", '’': "'", '“': "'", '”': "'", '–': "-", '—': "-", '…': "...",
+}
+
+// scriptLetters spells the Cyrillic and Greek letters in lowercase; accented
+// and other composed letters decompose into these first.
+var scriptLetters = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh",
+	'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ы': "y", 'э': "e",
+	'ю': "yu", 'я': "ya", 'і': "i", 'є': "ye", 'ґ': "g",
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+var stripMarks = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// NFC returns value in composed form, the one TMDB titles use.
+func NFC(value string) string {
+	return norm.NFC.String(value)
+}
+
+// StripMarks removes the accents and other combining marks of value.
+func StripMarks(value string) string {
+	stripped, _, err := transform.String(stripMarks, value)
+	if err != nil {
+		return value
+	}
+	return stripped
+}
+
+// Transliterate returns the closest ASCII spelling of value: accents are
+// removed, a few letters and the Cyrillic and Greek alphabets are spelled
+// out and the remaining non-ASCII characters are dropped.
+func Transliterate(value string) string {
+	value = StripMarks(value)
+
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case r < utf8.RuneSelf:
+			b.WriteRune(r)
+		case transliterations[r] != "":
+			b.WriteString(transliterations[r])
+		case scriptLetters[unicode.ToLower(r)] != "":
+			letters := scriptLetters[unicode.ToLower(r)]
+			if unicode.IsUpper(r) {
+				letters = strings.ToUpper(letters[:1]) + letters[1:]
+			}
+			b.WriteString(letters)
+		}
+	}
+	return b.String()
+}
+
+// Fold lowercases value, removes its accents and collapses its spaces. Unlike
+// Key, it keeps the script and punctuation of value, so the folded value can
+// still be searched.
+func Fold(value string) string {
+	return strings.Join(strings.Fields(strings.ToLower(StripMarks(value))), " ")
+}
+
+// Key returns the comparison key of a title: transliterated, lowercased,
+// without punctuation, with "&" spelled "and" and Roman numerals written in
+// Arabic, so "Rocky II" and "rocky 2" share the key "rocky 2". A single
+// letter is only a numeral as the last word, as in "Rocky V": elsewhere it is
+// more often a word, as in "I, Robot".
+func Key(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(Transliterate(strings.ReplaceAll(title, "&", " and "))), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	})
+	for i, word := range words {
+		if len(word) == 1 && i < len(words)-1 {
+			continue
+		}
+		if n, ok := parseRoman(word); ok {
+			words[i] = strconv.Itoa(n)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+var romanDigits = []struct {
+	value  int
+	letter string
+}{{10, "x"}, {9, "ix"}, {5, "v"}, {4, "iv"}, {1, "i"}}
+
+// parseRoman returns the value of a lowercase Roman numeral up to maxNumeral.
+func parseRoman(word string) (int, bool) {
+	if word == "" || strings.Trim(word, "ivx") != "" {
+		return 0, false
+	}
+	n, rest := 0, word
+	for _, digit := range romanDigits {
+		for strings.HasPrefix(rest, digit.letter) {
+			n += digit.value
+			rest = rest[len(digit.letter):]
+		}
+	}
+	// Only the canonical spelling is a numeral: "iiii" or "vx" are not.
+	if rest != "" || n > maxNumeral || formatRoman(n) != strings.ToUpper(word) {
+		return 0, false
+	}
+	return n, true
+}
+
+// formatRoman writes n as an uppercase Roman numeral.
+func formatRoman(n int) string {
+	var b strings.Builder
+	for _, digit := range romanDigits {
+		for n >= digit.value {
+			b.WriteString(digit.letter)
+			n -= digit.value
+		}
+	}
+	return strings.ToUpper(b.String())
+}
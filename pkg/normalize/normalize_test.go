@@ -0,0 +1,49 @@
+package normalize
+
+import "testing"
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"Amélie", "amelie"},
+		{"Ame\u0301lie", "Amélie"}, // NFD, as copied from macOS
+		{"Rocky II", "Rocky 2"},
+		{"Star Wars: Episode IV - A New Hope", "star wars episode 4 a new hope"},
+		{"Fast & Furious", "Fast and Furious"},
+		{"Брат 2", "Brat II"},
+		{"Léon", "LEON"},
+		{"Rocky V", "Rocky 5"},
+	}
+	for _, tt := range tests {
+		if Key(tt.a) != Key(tt.b) {
+			t.Errorf("Key(%q) = %q, Key(%q) = %q, want equal", tt.a, Key(tt.a), tt.b, Key(tt.b))
+		}
+	}
+	kept := map[string]string{
+		"Mix Civil":                  "mix civil",
+		"I, Robot":                   "i robot",
+		"V for Vendetta":             "v for vendetta",
+		"X-Men: Days of Future Past": "x men days of future past",
+	}
+	for title, want := range kept {
+		if got := Key(title); got != want {
+			t.Errorf("Key(%q) = %q, want words that only look like numerals kept", title, got)
+		}
+	}
+}
+
+func TestTransliterate(t *testing.T) {
+	tests := map[string]string{
+		"Amélie":   "Amelie",
+		"Брат 2":   "Brat 2",
+		"Ζορμπάς":  "Zormpas",
+		"Straße":   "Strasse",
+		"千と千尋の神隠し": "",
+	}
+	for value, want := range tests {
+		if got := Transliterate(value); got != want {
+			t.Errorf("Transliterate(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package pattern
+
+const (
+	FieldName             = "name"
+	FieldYear             = "year"
+	FieldDate             = "date"
+	FieldExt              = "extension"
+	FieldSeason           = "season"
+	FieldEpisode          = "episode"
+	FieldEpisodeTitle     = "episode_title"
+	FieldTmdbID           = "tmdb_id"
+	FieldImdbID           = "imdb_id"
+	FieldOriginalTitle    = "original_title"
+	FieldGenre            = "genre"
+	FieldStudio           = "studio"
+	FieldNetwork          = "network"
+	FieldRuntime          = "runtime"
+	FieldRating           = "rating"
+	FieldCertification    = "certification"
+	FieldShowYear         = "show_year"
+	FieldEpisodeAirDate   = "episode_air_date"
+	FieldOriginalFilename = "original_filename"
+	FieldResolution       = "resolution"
+	FieldSource           = "source"
+	FieldVideoCodec       = "vcodec"
+	FieldHDR              = "hdr"
+	FieldAudio            = "audio"
+	FieldGroup            = "group"
+	FieldDuration         = "duration"
+	FieldAudioLanguages   = "audio_languages"
+	FieldSubtitles        = "subtitle_languages"
+	FieldEdition          = "edition"
+	FieldPart             = "part"
+	FieldExtra            = "extra"
+	FieldEpisodeEnd       = "episode_end"
+	FieldSortName         = "sort_name"
+	FieldInitial          = "initial"
+	FieldCollection       = "collection"
+	FieldTvdbID           = "tvdb_id"
+	FieldOriginalLanguage = "original_language"
+	FieldCountry          = "country"
+)
+
+// technicalFields come from the release name or the container headers and
+// are shared by every media type.
+var technicalFields = []string{
+	FieldResolution,
+	FieldSource,
+	FieldVideoCodec,
+	FieldHDR,
+	FieldAudio,
+	FieldGroup,
+	FieldDuration,
+	FieldAudioLanguages,
+	FieldSubtitles,
+}
+
+// numberFields are rendered as numbers, the only fields a width format such
+// as {episode:3} applies to.
+var numberFields = []string{
+	FieldSeason,
+	FieldEpisode,
+	FieldEpisodeEnd,
+	FieldRuntime,
+	FieldPart,
+	FieldDuration,
+}
+
+// MovieFields are the fields available in movie patterns.
+var MovieFields = append([]string{
+	FieldName,
+	FieldSortName,
+	FieldInitial,
+	FieldYear,
+	FieldDate,
+	FieldExt,
+	FieldTmdbID,
+	FieldImdbID,
+	FieldOriginalTitle,
+	FieldOriginalLanguage,
+	FieldCountry,
+	FieldGenre,
+	FieldStudio,
+	FieldRuntime,
+	FieldRating,
+	FieldCertification,
+	FieldOriginalFilename,
+	FieldEdition,
+	FieldPart,
+	FieldExtra,
+	FieldCollection,
+}, technicalFields...)
+
+// EpisodeFields are the fields available in tv show patterns.
+var EpisodeFields = append([]string{
+	FieldName,
+	FieldSortName,
+	FieldInitial,
+	FieldYear,
+	FieldSeason,
+	FieldEpisode,
+	FieldEpisodeEnd,
+	FieldEpisodeTitle,
+	FieldExt,
+	FieldTmdbID,
+	FieldImdbID,
+	FieldTvdbID,
+	FieldOriginalTitle,
+	FieldOriginalLanguage,
+	FieldCountry,
+	FieldGenre,
+	FieldStudio,
+	FieldNetwork,
+	FieldRuntime,
+	FieldRating,
+	FieldCertification,
+	FieldShowYear,
+	FieldEpisodeAirDate,
+	FieldOriginalFilename,
+}, technicalFields...)
+
+// DetailFields are the fields that need the movie or show details, which cost
+// an extra TMDB request.
+var DetailFields = []string{
+	FieldImdbID,
+	FieldTvdbID,
+	FieldOriginalTitle,
+	FieldOriginalLanguage,
+	FieldCountry,
+	FieldGenre,
+	FieldStudio,
+	FieldNetwork,
+	FieldRuntime,
+	FieldCertification,
+	FieldCollection,
+}
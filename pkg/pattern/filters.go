@@ -0,0 +1,123 @@
+package pattern
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type argKind int
+
+const (
+	noArg argKind = iota
+	intArg
+	textArg
+)
+
+type filter struct {
+	arg   argKind
+	apply func(v Value, arg string, n int) Value
+}
+
+var filters = map[string]filter{
+	"upper": {apply: func(v Value, _ string, _ int) Value {
+		return Text(strings.ToUpper(v.String()))
+	}},
+	"lower": {apply: func(v Value, _ string, _ int) Value {
+		return Text(strings.ToLower(v.String()))
+	}},
+	"trim": {apply: func(v Value, _ string, _ int) Value {
+		return Text(strings.TrimSpace(v.String()))
+	}},
+	"truncate": {arg: intArg, apply: truncate},
+	"pad":      {arg: intArg, apply: pad},
+	"default": {arg: textArg, apply: func(v Value, arg string, _ int) Value {
+		if v.IsZero() {
+			return Text(arg)
+		}
+		return v
+	}},
+}
+
+// truncate cuts the value to n characters without leaving trailing spaces.
+func truncate(v Value, _ string, n int) Value {
+	s := v.String()
+	if utf8.RuneCountInString(s) <= n {
+		return v
+	}
+	return Text(strings.TrimRight(string([]rune(s)[:n]), " "))
+}
+
+// pad zero-pads numbers and right-pads text with spaces to n characters.
+func pad(v Value, _ string, n int) Value {
+	if v.isNumber {
+		return PaddedNumber(v.number, n)
+	}
+	s := v.String()
+	if missing := n - utf8.RuneCountInString(s); missing > 0 {
+		s += strings.Repeat(" ", missing)
+	}
+	return Text(s)
+}
+
+type appliedFilter struct {
+	filter
+	arg string
+	n   int
+}
+
+func (f appliedFilter) apply(v Value) Value {
+	return f.filter.apply(v, f.arg, f.n)
+}
+
+func parseFilter(spec string) (appliedFilter, error) {
+	name, arg, hasArg := strings.Cut(spec, ":")
+	name = strings.TrimSpace(name)
+
+	f, ok := filters[name]
+	if !ok {
+		return appliedFilter{}, fmt.Errorf("unknown filter %q", name)
+	}
+
+	applied := appliedFilter{filter: f}
+	switch f.arg {
+	case noArg:
+		if hasArg {
+			return appliedFilter{}, fmt.Errorf("filter %q takes no argument", name)
+		}
+	case intArg:
+		n, err := strconv.Atoi(strings.TrimSpace(arg))
+		if !hasArg || err != nil || n < 1 {
+			return appliedFilter{}, fmt.Errorf("filter %q needs a positive number, e.g. %s:10", name, name)
+		}
+		applied.n = n
+	case textArg:
+		if !hasArg {
+			return appliedFilter{}, fmt.Errorf("filter %q needs an argument, e.g. %s:unknown", name, name)
+		}
+		applied.arg = unquote(arg)
+	}
+	return applied, nil
+}
+
+// parseFormat parses the number format of a field, written {episode:3} or
+// {episode:pad=3}, as a leading pad filter.
+func parseFormat(spec string) (appliedFilter, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "pad="); ok {
+		spec = strings.TrimSpace(rest)
+	}
+	n, err := strconv.Atoi(spec)
+	if err != nil || n < 1 {
+		return appliedFilter{}, fmt.Errorf("format %q needs a positive width, e.g. :3 or :pad=3", spec)
+	}
+	return appliedFilter{filter: filters["pad"], n: n}, nil
+}
+
+func unquote(arg string) string {
+	if s, err := strconv.Unquote(strings.TrimSpace(arg)); err == nil {
+		return s
+	}
+	return arg
+}
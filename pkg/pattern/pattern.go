@@ -0,0 +1,353 @@
+// Package pattern implements the small template language used by the renamer
+// patterns.
+//
+//	{name}                     field value
+//	{name|upper|truncate:20}   field value passed through filters
+//	{episode:3}                number zero-padded to 3 digits, same as {episode:pad=3}
+//	{year|default:unknown}     fallback when the field is empty
+//	{if year} ({year}){end}    section rendered only when the field is set
+//	{if not year}...{else}...{end}
+//	{{ and }}                  literal braces
+//
+// Plain patterns such as "{name} - {year}{extension}" keep working unchanged.
+package pattern
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+var fieldNameRegex = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// Error is a parse error with the position at which it was detected.
+type Error struct {
+	Pattern string
+	Column  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Message)
+}
+
+// Template is a parsed pattern.
+type Template struct {
+	source string
+	nodes  []node
+}
+
+type node interface {
+	render(b *strings.Builder, values Values, escape func(string) string)
+}
+
+type textNode string
+
+func (n textNode) render(b *strings.Builder, _ Values, _ func(string) string) {
+	b.WriteString(string(n))
+}
+
+type fieldNode struct {
+	name    string
+	filters []appliedFilter
+}
+
+func (n fieldNode) render(b *strings.Builder, values Values, escape func(string) string) {
+	v := values[n.name]
+	for _, f := range n.filters {
+		v = f.apply(v)
+	}
+	if escape != nil {
+		b.WriteString(escape(v.String()))
+		return
+	}
+	b.WriteString(v.String())
+}
+
+type ifNode struct {
+	field     string
+	negate    bool
+	then, alt []node
+}
+
+func (n ifNode) render(b *strings.Builder, values Values, escape func(string) string) {
+	branch := n.then
+	if values[n.field].IsZero() != n.negate {
+		branch = n.alt
+	}
+	for _, child := range branch {
+		child.render(b, values, escape)
+	}
+}
+
+// Parse parses source, accepting only the given field names.
+func Parse(source string, fields []string) (*Template, error) {
+	p := &parser{source: source, fields: make(map[string]bool, len(fields))}
+	for _, f := range fields {
+		p.fields[f] = true
+	}
+
+	nodes, end, err := p.parseNodes()
+	if err != nil {
+		return nil, err
+	}
+	if end != "" {
+		return nil, p.errorf(p.tagStart, "unexpected {%s} without {if}", end)
+	}
+	return &Template{source: source, nodes: nodes}, nil
+}
+
+// Execute renders the template with values. Missing fields render empty.
+func (t *Template) Execute(values Values) string {
+	return t.ExecuteEscaped(values, nil)
+}
+
+// ExecuteEscaped renders the template, passing every field output through
+// escape once its filters are applied. The literal text of the pattern is left
+// untouched.
+func (t *Template) ExecuteEscaped(values Values, escape func(string) string) string {
+	var b strings.Builder
+	for _, n := range t.nodes {
+		n.render(&b, values, escape)
+	}
+	return b.String()
+}
+
+func (t *Template) String() string {
+	return t.source
+}
+
+// References reports whether the template uses field, either as a value or in
+// a condition.
+func (t *Template) References(field string) bool {
+	return references(t.nodes, field)
+}
+
+// ReferencesAny reports whether the template uses at least one of fields.
+func (t *Template) ReferencesAny(fields []string) bool {
+	for _, f := range fields {
+		if t.References(f) {
+			return true
+		}
+	}
+	return false
+}
+
+func references(nodes []node, field string) bool {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case fieldNode:
+			if n.name == field {
+				return true
+			}
+		case ifNode:
+			if n.field == field || references(n.then, field) || references(n.alt, field) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type parser struct {
+	source   string
+	fields   map[string]bool
+	pos      int
+	tagStart int
+}
+
+func (p *parser) errorf(offset int, format string, args ...any) *Error {
+	return &Error{
+		Pattern: p.source,
+		Column:  utf8.RuneCountInString(p.source[:offset]) + 1,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// parseNodes parses until the end of the source or an {else}/{end} tag, which
+// is returned so that the enclosing {if} can handle it.
+func (p *parser) parseNodes() ([]node, string, error) {
+	var nodes []node
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, textNode(text.String()))
+			text.Reset()
+		}
+	}
+
+	for p.pos < len(p.source) {
+		c := p.source[p.pos]
+		switch {
+		case strings.HasPrefix(p.source[p.pos:], "{{"):
+			text.WriteByte('{')
+			p.pos += 2
+		case strings.HasPrefix(p.source[p.pos:], "}}"):
+			text.WriteByte('}')
+			p.pos += 2
+		case c == '}':
+			return nil, "", p.errorf(p.pos, "unexpected '}' (use '}}' for a literal brace)")
+		case c == '{':
+			flush()
+			n, end, err := p.parseTag()
+			if err != nil {
+				return nil, "", err
+			}
+			if end != "" {
+				return nodes, end, nil
+			}
+			nodes = append(nodes, n)
+		default:
+			text.WriteByte(c)
+			p.pos++
+		}
+	}
+	flush()
+	return nodes, "", nil
+}
+
+func (p *parser) parseTag() (node, string, error) {
+	start := p.pos
+	p.tagStart = start
+	body, err := p.readTag()
+	if err != nil {
+		return nil, "", err
+	}
+	trimmed := strings.TrimSpace(body)
+
+	switch {
+	case trimmed == "else" || trimmed == "end":
+		return nil, trimmed, nil
+	case strings.HasPrefix(trimmed, "if ") || trimmed == "if":
+		return p.parseIf(start, strings.TrimSpace(strings.TrimPrefix(trimmed, "if")))
+	}
+
+	return p.parseField(start, body)
+}
+
+// readTag reads a {...} tag and returns its content. Double quotes allow
+// filter arguments to contain '|' or '}'.
+func (p *parser) readTag() (string, error) {
+	start := p.pos
+	p.pos++
+	inQuotes := false
+	for p.pos < len(p.source) {
+		switch p.source[p.pos] {
+		case '"':
+			inQuotes = !inQuotes
+		case '{':
+			if !inQuotes {
+				return "", p.errorf(p.pos, "unexpected '{' inside tag")
+			}
+		case '}':
+			if !inQuotes {
+				body := p.source[start+1 : p.pos]
+				p.pos++
+				return body, nil
+			}
+		}
+		p.pos++
+	}
+	if inQuotes {
+		return "", p.errorf(start, "unterminated quote in tag")
+	}
+	return "", p.errorf(start, "unclosed '{'")
+}
+
+func (p *parser) parseIf(start int, condition string) (node, string, error) {
+	n := ifNode{}
+	if rest, ok := strings.CutPrefix(condition, "not "); ok {
+		n.negate = true
+		condition = strings.TrimSpace(rest)
+	}
+	if condition == "" {
+		return nil, "", p.errorf(start, "{if} needs a field name")
+	}
+	if err := p.checkField(start, condition); err != nil {
+		return nil, "", err
+	}
+	n.field = condition
+
+	var end string
+	var err error
+	n.then, end, err = p.parseNodes()
+	if err != nil {
+		return nil, "", err
+	}
+	if end == "else" {
+		n.alt, end, err = p.parseNodes()
+		if err != nil {
+			return nil, "", err
+		}
+		if end == "else" {
+			return nil, "", p.errorf(p.tagStart, "{if %s} has more than one {else}", n.field)
+		}
+	}
+	if end != "end" {
+		return nil, "", p.errorf(start, "{if %s} is never closed with {end}", n.field)
+	}
+	return n, "", nil
+}
+
+func (p *parser) parseField(start int, body string) (node, string, error) {
+	parts := splitFilters(body)
+	name, format, hasFormat := strings.Cut(parts[0], ":")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", p.errorf(start, "empty field name")
+	}
+	if err := p.checkField(start, name); err != nil {
+		return nil, "", err
+	}
+
+	n := fieldNode{name: name}
+	if hasFormat {
+		if !slices.Contains(numberFields, name) {
+			return nil, "", p.errorf(start, "field %q is not a number and takes no width, use the pad filter instead", name)
+		}
+		f, err := parseFormat(format)
+		if err != nil {
+			return nil, "", p.errorf(start, "field %q: %v", name, err)
+		}
+		n.filters = append(n.filters, f)
+	}
+	for _, part := range parts[1:] {
+		f, err := parseFilter(part)
+		if err != nil {
+			return nil, "", p.errorf(start, "field %q: %v", name, err)
+		}
+		n.filters = append(n.filters, f)
+	}
+	return n, "", nil
+}
+
+func (p *parser) checkField(start int, name string) error {
+	if !fieldNameRegex.MatchString(name) {
+		return p.errorf(start, "invalid field name %q", name)
+	}
+	if !p.fields[name] {
+		return p.errorf(start, "unknown field %q", name)
+	}
+	return nil
+}
+
+// splitFilters splits a tag body on '|' outside of double quotes.
+func splitFilters(body string) []string {
+	var parts []string
+	inQuotes := false
+	last := 0
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case '|':
+			if !inQuotes {
+				parts = append(parts, body[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(parts, body[last:])
+}
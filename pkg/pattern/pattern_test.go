@@ -0,0 +1,112 @@
+package pattern
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExecute(t *testing.T) {
+	values := Values{
+		FieldName:         Text("The Matrix"),
+		FieldYear:         Text("1999"),
+		FieldExt:          Text(".mkv"),
+		FieldSeason:       PaddedNumber(1, 2),
+		FieldEpisode:      PaddedNumber(5, 2),
+		FieldEpisodeTitle: Text(""),
+	}
+
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"{name} - {year}{extension}", "The Matrix - 1999.mkv"},
+		{"{name} - {season}x{episode}{extension}", "The Matrix - 01x05.mkv"},
+		{"{name}{if year} ({year}){end}{extension}", "The Matrix (1999).mkv"},
+		{"{name}{if episode_title} - {episode_title}{end}", "The Matrix"},
+		{"{if not episode_title}untitled{else}{episode_title}{end}", "untitled"},
+		{"{name|upper}", "THE MATRIX"},
+		{"{name|lower|truncate:7}", "the mat"},
+		{"{name|truncate:4}", "The"},
+		{"{episode_title|default:TBA}", "TBA"},
+		{`{episode_title|default:"A | B"}`, "A | B"},
+		{"{season|pad:3}", "001"},
+		{"Season {season:1}", "Season 1"},
+		{"{episode:3}", "005"},
+		{"{season:pad=3}x{episode:1}", "001x5"},
+		{"{episode:1|pad:2}", "05"},
+		{"{year|pad:6}|", "1999  |"},
+		{"{{edition-{name}}}", "{edition-The Matrix}"},
+		{"no fields", "no fields"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			tmpl, err := Parse(tt.pattern, EpisodeFields)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := tmpl.Execute(values); got != tt.want {
+				t.Errorf("Execute() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		column  int
+	}{
+		{"{name} - {nmae}", 10},
+		{"{name", 1},
+		{"{name}}x}", 7},
+		{"{name|shout}", 1},
+		{"{name|truncate:abc}", 1},
+		{"{if year}({year})", 1},
+		{"{name}{end}", 7},
+		{"{if year}a{else}b{else}c{end}", 18},
+		{"{na{me}", 4},
+		{"{name} {season:pad=x}", 8},
+		{"{episode:0}", 1},
+		{"{name} {name:3}", 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			_, err := Parse(tt.pattern, MovieFields)
+			var perr *Error
+			if !errors.As(err, &perr) {
+				t.Fatalf("Parse() error = %v, want *Error", err)
+			}
+			if perr.Column != tt.column {
+				t.Errorf("Parse() error column = %d, want %d (%v)", perr.Column, tt.column, err)
+			}
+		})
+	}
+}
+
+func TestReferences(t *testing.T) {
+	tmpl, err := Parse("{name}{if year} ({year|default:x}){end}", MovieFields)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !tmpl.References(FieldYear) || !tmpl.References(FieldName) {
+		t.Error("References() expected name and year to be referenced")
+	}
+	if tmpl.References(FieldExt) {
+		t.Error("References() expected extension not to be referenced")
+	}
+}
+
+func TestExecuteEscaped(t *testing.T) {
+	tmpl, err := Parse("Movies/{name|upper}{extension}", MovieFields)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	escape := func(s string) string { return strings.ReplaceAll(s, "/", "-") }
+	got := tmpl.ExecuteEscaped(Values{FieldName: Text("AC/DC"), FieldExt: Text(".mkv")}, escape)
+	if want := "Movies/AC-DC.mkv"; got != want {
+		t.Errorf("ExecuteEscaped() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+package pattern
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Value is the value of a pattern field, either text or a number rendered
+// with a minimum zero-padded width.
+type Value struct {
+	text     string
+	number   int
+	width    int
+	isNumber bool
+}
+
+// Values maps field names to their values.
+type Values map[string]Value
+
+func Text(s string) Value {
+	return Value{text: s}
+}
+
+func Number(n int) Value {
+	return Value{number: n, isNumber: true}
+}
+
+// PaddedNumber is a number rendered zero-padded to width, e.g. 5 → "05".
+func PaddedNumber(n, width int) Value {
+	return Value{number: n, width: width, isNumber: true}
+}
+
+// IsZero reports whether the value is empty text or the number zero, which is
+// how {if} and the default filter treat a field as unset.
+func (v Value) IsZero() bool {
+	if v.isNumber {
+		return v.number == 0
+	}
+	return v.text == ""
+}
+
+func (v Value) String() string {
+	if !v.isNumber {
+		return v.text
+	}
+	if v.width > 0 {
+		return fmt.Sprintf("%0*d", v.width, v.number)
+	}
+	return strconv.Itoa(v.number)
+}
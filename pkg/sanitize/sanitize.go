@@ -0,0 +1,172 @@
+// Package sanitize turns rendered names into paths that every target
+// filesystem accepts.
+//
+// Field values (titles, episode names...) can't contain path separators, so
+// "AC/DC: Live" never creates an "AC" folder. The whole rendered path is then
+// cleaned component by component: reserved names, trailing dots and spaces,
+// and components longer than the filesystem limit.
+package sanitize
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nouuu/gonamer/pkg/normalize"
+)
+
+// Mode selects the set of characters and names that are allowed.
+type Mode string
+
+const (
+	// POSIX only forbids the path separator and NUL.
+	POSIX Mode = "posix"
+	// Windows is safe for NTFS and SMB shares: no <>:"/\|?* characters, no
+	// reserved device names and no trailing dots or spaces.
+	Windows Mode = "windows"
+	// ASCII is Windows with every character transliterated to ASCII.
+	ASCII Mode = "ascii"
+)
+
+// DefaultMaxLength is the limit of a path component on most filesystems, in
+// bytes.
+const DefaultMaxLength = 255
+
+// maxExtLength bounds what is kept as an extension when truncating, so that a
+// dot in a long title isn't mistaken for one.
+const maxExtLength = 16
+
+// IsValid reports whether m is a known mode.
+func (m Mode) IsValid() bool {
+	switch m {
+	case POSIX, Windows, ASCII:
+		return true
+	}
+	return false
+}
+
+// Sanitizer cleans values and paths for a mode.
+type Sanitizer struct {
+	mode      Mode
+	maxLength int
+}
+
+// New returns a sanitizer for mode. maxLength is the maximum size of a path
+// component in bytes; DefaultMaxLength is used when it isn't positive.
+func New(mode Mode, maxLength int) *Sanitizer {
+	if !mode.IsValid() {
+		mode = Windows
+	}
+	if maxLength <= 0 {
+		maxLength = DefaultMaxLength
+	}
+	return &Sanitizer{mode: mode, maxLength: maxLength}
+}
+
+// windowsReplacer rewrites the characters NTFS refuses. Colons mostly separate
+// a title from its subtitle, so they become " - " rather than disappearing.
+var windowsReplacer = strings.NewReplacer(
+	": ", " - ",
+	":", "-",
+	"/", "-",
+	`\`, "-",
+	"|", "-",
+	`"`, "'",
+	"?", "",
+	"*", "",
+	"<", "",
+	">", "",
+)
+
+var posixReplacer = strings.NewReplacer("/", "-")
+
+// Value cleans a single field value: separators and illegal characters are
+// replaced so that the value stays within one path component.
+func (s *Sanitizer) Value(value string) string {
+	if s.mode == POSIX {
+		value = posixReplacer.Replace(value)
+	} else {
+		value = windowsReplacer.Replace(value)
+	}
+	return s.clean(value)
+}
+
+// Path cleans every component of a rendered path. Separators are kept, so
+// patterns can still create folders.
+func (s *Sanitizer) Path(path string) string {
+	volume := filepath.VolumeName(path)
+	rest := filepath.ToSlash(path[len(volume):])
+
+	components := strings.Split(rest, "/")
+	for i, component := range components {
+		if component == "" || component == "." || component == ".." {
+			continue
+		}
+		if s.mode != POSIX {
+			component = windowsReplacer.Replace(component)
+		}
+		component = s.clean(component)
+		if s.mode != POSIX {
+			component = strings.TrimRight(component, ". ")
+			component = escapeReserved(component)
+		}
+		components[i] = truncate(component, s.maxLength, i == len(components)-1)
+	}
+	return volume + filepath.FromSlash(strings.Join(components, "/"))
+}
+
+// clean removes control characters and, in ASCII mode, transliterates the
+// value.
+func (s *Sanitizer) clean(value string) string {
+	if s.mode == ASCII {
+		value = normalize.Transliterate(value)
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, value)
+}
+
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// escapeReserved suffixes Windows device names, which are reserved whatever
+// their extension: "CON.mkv" becomes "CON_.mkv".
+func escapeReserved(component string) string {
+	base, ext, hasExt := strings.Cut(component, ".")
+	if !reservedNames[strings.ToUpper(strings.TrimSpace(base))] {
+		return component
+	}
+	if !hasExt {
+		return base + "_"
+	}
+	return base + "_." + ext
+}
+
+// truncate shortens component to maxLength bytes without splitting a
+// character. The extension of the last component is preserved.
+func truncate(component string, maxLength int, keepExt bool) string {
+	if len(component) <= maxLength {
+		return component
+	}
+	var ext string
+	if keepExt {
+		if e := filepath.Ext(component); len(e) <= maxExtLength && len(e) < maxLength {
+			ext = e
+		}
+	}
+	name := strings.TrimSuffix(component, ext)
+	limit := maxLength - len(ext)
+	for limit > 0 && !utf8.RuneStart(name[limit]) {
+		limit--
+	}
+	return strings.TrimRight(name[:limit], ". ") + ext
+}
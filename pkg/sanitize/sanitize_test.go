@@ -0,0 +1,66 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		mode  Mode
+		value string
+		want  string
+	}{
+		{POSIX, "AC/DC: Live", "AC-DC: Live"},
+		{Windows, "AC/DC: Live", "AC-DC - Live"},
+		{Windows, "Mission: Impossible", "Mission - Impossible"},
+		{Windows, "What If...?", "What If..."},
+		{Windows, `The "Best" <Show>`, "The 'Best' Show"},
+		{Windows, "Tab\there", "Tabhere"},
+		{ASCII, "Amélie: Le Fabuleux Destin", "Amelie - Le Fabuleux Destin"},
+		{ASCII, "Straße – Ørsted’s", "Strasse - Orsted's"},
+		{ASCII, "Брат 2", "Brat 2"},
+		{ASCII, "Ζορμπάς", "Zormpas"},
+	}
+	for _, tt := range tests {
+		if got := New(tt.mode, 0).Value(tt.value); got != tt.want {
+			t.Errorf("%s: Value(%q) = %q, want %q", tt.mode, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		mode Mode
+		path string
+		want string
+	}{
+		{POSIX, "Movies/What If...?.mkv", "Movies/What If...?.mkv"},
+		{Windows, "Movies/What If...?.mkv", "Movies/What If....mkv"},
+		{Windows, "Shows/Name./Season 1 /S01E01.mkv", "Shows/Name/Season 1/S01E01.mkv"},
+		{Windows, "CON.mkv", "CON_.mkv"},
+		{Windows, "Movies/nul", "Movies/nul_"},
+		{Windows, "Console.mkv", "Console.mkv"},
+		{POSIX, "/media/Movies/CON.mkv", "/media/Movies/CON.mkv"},
+	}
+	for _, tt := range tests {
+		if got := New(tt.mode, 0).Path(tt.path); got != tt.want {
+			t.Errorf("%s: Path(%q) = %q, want %q", tt.mode, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPathTruncatesKeepingExtension(t *testing.T) {
+	s := New(POSIX, 20)
+
+	if got := s.Path(strings.Repeat("a", 30) + ".mkv"); got != strings.Repeat("a", 16)+".mkv" {
+		t.Errorf("file name = %q", got)
+	}
+	if got := s.Path(strings.Repeat("a", 30) + "/x.mkv"); got != strings.Repeat("a", 20)+"/x.mkv" {
+		t.Errorf("folder name = %q", got)
+	}
+	// "é" is two bytes and must not be split.
+	if got := s.Path(strings.Repeat("é", 20) + ".mkv"); got != strings.Repeat("é", 8)+".mkv" {
+		t.Errorf("multi-byte name = %q", got)
+	}
+}
@@ -0,0 +1,80 @@
+// Package sortname builds the titles libraries are sorted by, with the
+// leading article moved to the end ("The Matrix" sorts as "Matrix, The"),
+// and the letter a title is filed under.
+package sortname
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nouuu/gonamer/pkg/normalize"
+)
+
+// DefaultArticles are the leading articles of each language. English titles
+// are common in every library, so "The" is moved in the other languages too.
+// Articles ending with an apostrophe are elided and followed by the word
+// directly, as in "L'Odyssée".
+var DefaultArticles = map[string][]string{
+	"en": {"The", "A", "An"},
+	"fr": {"Le", "La", "Les", "L'", "Un", "Une", "Des", "The"},
+	"de": {"Der", "Die", "Das", "Ein", "Eine", "The"},
+	"es": {"El", "La", "Los", "Las", "Un", "Una", "The"},
+	"it": {"Il", "Lo", "La", "I", "Gli", "Le", "L'", "Un", "Una", "Uno", "The"},
+	"pt": {"O", "A", "Os", "As", "Um", "Uma", "The"},
+	"nl": {"De", "Het", "Een", "The"},
+}
+
+// Sorter moves the articles of one language.
+type Sorter struct {
+	articles []string
+}
+
+// New returns a sorter for language, an ISO 639-1 code optionally followed by
+// a region as in "fr-FR". The articles of a language found in overrides
+// replace the default ones. Unknown languages use the English articles.
+func New(language string, overrides map[string][]string) *Sorter {
+	language, _, _ = strings.Cut(strings.ToLower(language), "-")
+	if articles, ok := overrides[language]; ok {
+		return &Sorter{articles: articles}
+	}
+	if articles, ok := DefaultArticles[language]; ok {
+		return &Sorter{articles: articles}
+	}
+	return &Sorter{articles: DefaultArticles["en"]}
+}
+
+// SortName returns title with its leading article moved to the end, or title
+// itself when it doesn't start with one.
+func (s *Sorter) SortName(title string) string {
+	title = strings.TrimSpace(title)
+	for _, article := range s.articles {
+		if len(title) <= len(article) || !strings.EqualFold(title[:len(article)], article) {
+			continue
+		}
+		rest := title[len(article):]
+		if !strings.HasSuffix(article, "'") {
+			if !strings.HasPrefix(rest, " ") {
+				continue
+			}
+			rest = strings.TrimLeft(rest, " ")
+		}
+		if rest == "" {
+			continue
+		}
+		return rest + ", " + title[:len(article)]
+	}
+	return title
+}
+
+// Initial returns the uppercase letter name is filed under, transliterated
+// to ASCII, or "#" for names starting with a digit, a symbol or a letter
+// without transliteration.
+func Initial(name string) string {
+	r, _ := utf8.DecodeRuneInString(strings.TrimSpace(name))
+	letters := normalize.Transliterate(string(r))
+	if letters == "" || letters[0] > unicode.MaxASCII || !unicode.IsLetter(rune(letters[0])) {
+		return "#"
+	}
+	return strings.ToUpper(letters[:1])
+}
@@ -0,0 +1,53 @@
+package sortname
+
+import "testing"
+
+func TestSortName(t *testing.T) {
+	tests := []struct {
+		language string
+		title    string
+		want     string
+	}{
+		{"en-US", "The Matrix", "Matrix, The"},
+		{"en-US", "A Beautiful Mind", "Beautiful Mind, A"},
+		{"en-US", "Theodore Rex", "Theodore Rex"},
+		{"en-US", "The", "The"},
+		{"en-US", "Le Mans", "Le Mans"},
+		{"fr-FR", "Le Fabuleux Destin d'Amélie Poulain", "Fabuleux Destin d'Amélie Poulain, Le"},
+		{"fr-FR", "L'Odyssée de Pi", "Odyssée de Pi, L'"},
+		{"fr-FR", "The Dark Knight", "Dark Knight, The"},
+		{"fr", "Alien", "Alien"},
+		{"de-DE", "Das Boot", "Boot, Das"},
+		{"ja-JP", "The Ring", "Ring, The"},
+	}
+	for _, tt := range tests {
+		if got := New(tt.language, nil).SortName(tt.title); got != tt.want {
+			t.Errorf("%s: SortName(%q) = %q, want %q", tt.language, tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestSortNameOverrides(t *testing.T) {
+	s := New("en-US", map[string][]string{"en": {"The"}})
+	if got := s.SortName("A Quiet Place"); got != "A Quiet Place" {
+		t.Errorf("SortName() = %q, want the title unchanged", got)
+	}
+}
+
+func TestInitial(t *testing.T) {
+	tests := map[string]string{
+		"Matrix, The": "M",
+		"amélie":      "A",
+		"Éternel":     "E",
+		"12 Monkeys":  "#",
+		"(500) Days":  "#",
+		"Брат":        "B",
+		"千と千尋の神隠し":    "#",
+		"":            "#",
+	}
+	for name, want := range tests {
+		if got := Initial(name); got != want {
+			t.Errorf("Initial(%q) = %q, want %q", name, got, want)
+		}
+	}
+}